@@ -12,179 +12,107 @@ package cgo
 #include "zenoh.h"
 
 extern void goSubscriberCallback(void *sample, void *context);
+extern void goSubscriberDropCallback(void *context);
 
 static void cSubscriberCallback(struct z_loaned_sample_t *sample, void *context) {
     goSubscriberCallback((void*)sample, context);
 }
 
-static void createClosureSample(struct z_owned_closure_sample_t *closure, void *context) {
-    z_closure_sample(closure, cSubscriberCallback, NULL, context);
-}
-
-static void keyexprToString(const struct z_loaned_keyexpr_t *keyexpr, char *buf, size_t buf_len) {
-    struct z_view_string_t view_str;
-    z_keyexpr_as_view_string(keyexpr, &view_str);
-    const struct z_loaned_string_t *loaned_str = z_view_string_loan(&view_str);
-    size_t len = z_string_len(loaned_str);
-    const char *data = z_string_data(loaned_str);
-    if (len > 0 && len < buf_len) {
-        memcpy(buf, data, len);
-        buf[len] = '\0';
-    } else if (buf_len > 0) {
-        buf[0] = '\0';
-    }
+static void cSubscriberDropCallback(void *context) {
+    goSubscriberDropCallback(context);
 }
 
-static size_t samplePayloadToSlice(const struct z_loaned_sample_t *sample, uint8_t *buf, size_t buf_len) {
-    const struct z_loaned_bytes_t *payload = z_sample_payload(sample);
-    if (payload == NULL) {
-        return 0;
-    }
-    struct z_owned_slice_t slice;
-    z_bytes_to_slice(payload, &slice);
-    const struct z_loaned_slice_t *loaned = z_slice_loan(&slice);
-    size_t len = z_slice_len(loaned);
-    if (len > buf_len) {
-        len = buf_len;
-    }
-    if (len > 0) {
-        const uint8_t *data = z_slice_data(loaned);
-        memcpy(buf, data, len);
-    }
-    z_slice_drop((struct z_moved_slice_t *)&slice);
-    return len;
+static void createClosureSample(struct z_owned_closure_sample_t *closure, void *context) {
+    z_closure_sample(closure, cSubscriberCallback, cSubscriberDropCallback, context);
 }
 
 // Query Reply callback
 extern void goReplyCallback(void *reply, void *context);
+extern void goReplyDropCallback(void *context);
 
 static void cReplyCallback(struct z_loaned_reply_t *reply, void *context) {
     goReplyCallback((void*)reply, context);
 }
 
+static void cReplyDropCallback(void *context) {
+    goReplyDropCallback(context);
+}
+
 static void createClosureReply(struct z_owned_closure_reply_t *closure, void *context) {
-    z_closure_reply(closure, cReplyCallback, NULL, context);
+    z_closure_reply(closure, cReplyCallback, cReplyDropCallback, context);
 }
 
 // Query callback
 extern void goQueryCallback(void *query, void *context);
+extern void goQueryDropCallback(void *context);
 
 static void cQueryCallback(struct z_loaned_query_t *query, void *context) {
     goQueryCallback((void*)query, context);
 }
 
+static void cQueryDropCallback(void *context) {
+    goQueryDropCallback(context);
+}
+
 static void createClosureQuery(struct z_owned_closure_query_t *closure, void *context) {
-    z_closure_query(closure, cQueryCallback, NULL, context);
+    z_closure_query(closure, cQueryCallback, cQueryDropCallback, context);
 }
 
-// Query helper functions
-static void queryKeyexprToString(const struct z_loaned_query_t *query, char *buf, size_t buf_len) {
-    const struct z_loaned_keyexpr_t *keyexpr = z_query_keyexpr(query);
-    if (keyexpr == NULL || buf_len == 0) {
-        if (buf_len > 0) buf[0] = '\0';
-        return;
-    }
-    struct z_view_string_t view_str;
-    z_keyexpr_as_view_string(keyexpr, &view_str);
-    const struct z_loaned_string_t *loaned_str = z_view_string_loan(&view_str);
-    size_t len = z_string_len(loaned_str);
-    const char *data = z_string_data(loaned_str);
-    if (len > 0 && len < buf_len) {
-        memcpy(buf, data, len);
-        buf[len] = '\0';
-    } else if (buf_len > 0) {
-        buf[0] = '\0';
-    }
+// Hello callback
+extern void goHelloCallback(void *hello, void *context);
+
+static void cHelloCallback(struct z_loaned_hello_t *hello, void *context) {
+    goHelloCallback((void*)hello, context);
 }
 
-static size_t queryPayloadToSlice(const struct z_loaned_query_t *query, uint8_t *buf, size_t buf_len) {
-    struct z_loaned_bytes_t *payload = z_query_payload(query);
-    if (payload == NULL) {
-        return 0;
-    }
-    struct z_owned_slice_t slice;
-    z_bytes_to_slice(payload, &slice);
-    const struct z_loaned_slice_t *loaned = z_slice_loan(&slice);
-    size_t len = z_slice_len(loaned);
-    if (len > buf_len) {
-        len = buf_len;
-    }
-    if (len > 0) {
-        const uint8_t *data = z_slice_data(loaned);
-        memcpy(buf, data, len);
-    }
-    z_slice_drop((struct z_moved_slice_t *)&slice);
-    return len;
-}
-
-// Query parameters helper
-static size_t queryParametersToSlice(const struct z_loaned_query_t *query, char *buf, size_t buf_len) {
-    struct z_view_string_t params;
-    z_query_parameters(query, &params);
-    const struct z_loaned_string_t *loaned_str = z_view_string_loan(&params);
-    if (loaned_str == NULL || buf_len == 0) {
-        if (buf_len > 0) buf[0] = '\0';
-        return 0;
-    }
-    size_t len = z_string_len(loaned_str);
-    const char *data = z_string_data(loaned_str);
-    if (len > 0 && len < buf_len) {
-        memcpy(buf, data, len);
-        buf[len] = '\0';
-    } else if (buf_len > 0) {
-        buf[0] = '\0';
-    }
-    return len;
+static void createClosureHello(struct z_owned_closure_hello_t *closure, void *context) {
+    z_closure_hello(closure, cHelloCallback, NULL, context);
 }
 
-// Reply OK payload helper
-static size_t replyOkPayloadToSlice(const struct z_loaned_reply_t *reply, uint8_t *buf, size_t buf_len) {
-    struct z_loaned_sample_t *sample = z_reply_ok(reply);
-    if (sample == NULL) {
-        return 0;
-    }
-    const struct z_loaned_bytes_t *payload = z_sample_payload(sample);
-    if (payload == NULL) {
-        return 0;
-    }
-    struct z_owned_slice_t slice;
-    z_bytes_to_slice(payload, &slice);
-    const struct z_loaned_slice_t *loaned = z_slice_loan(&slice);
-    size_t len = z_slice_len(loaned);
-    if (len > buf_len) {
-        len = buf_len;
-    }
-    if (len > 0) {
-        const uint8_t *data = z_slice_data(loaned);
-        memcpy(buf, data, len);
-    }
-    z_slice_drop((struct z_moved_slice_t *)&slice);
-    return len;
+// Matching status callback
+extern void goMatchingStatusCallback(bool matching, void *context);
+extern void goMatchingStatusDropCallback(void *context);
+
+static void cMatchingStatusCallback(const struct z_matching_status_t *status, void *context) {
+    goMatchingStatusCallback(status->matching, context);
 }
 
-// Reply OK keyexpr helper
-static void replyOkKeyexprToString(const struct z_loaned_reply_t *reply, char *buf, size_t buf_len) {
-    struct z_loaned_sample_t *sample = z_reply_ok(reply);
-    if (sample == NULL || buf_len == 0) {
-        if (buf_len > 0) buf[0] = '\0';
-        return;
-    }
-    const struct z_loaned_keyexpr_t *keyexpr = z_sample_keyexpr(sample);
-    if (keyexpr == NULL) {
-        if (buf_len > 0) buf[0] = '\0';
-        return;
+static void cMatchingStatusDropCallback(void *context) {
+    goMatchingStatusDropCallback(context);
+}
+
+static void createClosureMatchingStatus(struct z_owned_closure_matching_status_t *closure, void *context) {
+    z_closure_matching_status(closure, cMatchingStatusCallback, cMatchingStatusDropCallback, context);
+}
+
+// ZID list collected by the session info closures
+typedef struct {
+    z_id_t *ids;
+    size_t len;
+    size_t cap;
+} zid_list_t;
+
+static void cZidCallback(const z_id_t *zid, void *context) {
+    zid_list_t *list = (zid_list_t *)context;
+    if (list->len == list->cap) {
+        size_t cap = list->cap == 0 ? 8 : list->cap * 2;
+        z_id_t *ids = realloc(list->ids, cap * sizeof(z_id_t));
+        if (ids == NULL) {
+            return;
+        }
+        list->ids = ids;
+        list->cap = cap;
     }
-    struct z_view_string_t view_str;
-    z_keyexpr_as_view_string(keyexpr, &view_str);
-    const struct z_loaned_string_t *loaned_str = z_view_string_loan(&view_str);
-    size_t len = z_string_len(loaned_str);
-    const char *data = z_string_data(loaned_str);
-    if (len > 0 && len < buf_len) {
-        memcpy(buf, data, len);
-        buf[len] = '\0';
-    } else if (buf_len > 0) {
-        buf[0] = '\0';
+    list->ids[list->len++] = *zid;
+}
+
+static z_result_t infoZids(const z_loaned_session_t *session, bool routers, zid_list_t *list) {
+    z_owned_closure_zid_t closure;
+    z_closure_zid(&closure, cZidCallback, NULL, list);
+    if (routers) {
+        return z_info_routers_zid(session, z_move(closure));
     }
+    return z_info_peers_zid(session, z_move(closure));
 }
 */
 import "C"
@@ -203,6 +131,44 @@ func Check(ret C.z_result_t) error {
 	return nil
 }
 
+// bytesToGo copies the full content of a loaned zenoh bytes object into Go memory.
+// Fragmented payloads are gathered slice by slice through z_bytes_get_slice_iterator,
+// so no intermediate contiguous C buffer is allocated.
+func bytesToGo(bytes *C.z_loaned_bytes_t) []byte {
+	if bytes == nil {
+		return nil
+	}
+	out := make([]byte, 0, int(C.z_bytes_len(bytes)))
+	it := C.z_bytes_get_slice_iterator(bytes)
+	var view C.z_view_slice_t
+	for C.z_bytes_slice_iterator_next(&it, &view) {
+		slice := C.z_view_slice_loan(&view)
+		if n := int(C.z_slice_len(slice)); n > 0 {
+			out = append(out, unsafe.Slice((*byte)(unsafe.Pointer(C.z_slice_data(slice))), n)...)
+		}
+	}
+	return out
+}
+
+// viewStringToGo copies a view string into a Go string.
+func viewStringToGo(view *C.z_view_string_t) string {
+	loaned := C.z_view_string_loan(view)
+	if loaned == nil {
+		return ""
+	}
+	return C.GoStringN(C.z_string_data(loaned), C.int(C.z_string_len(loaned)))
+}
+
+// keyexprToGo returns the string form of a loaned key expression.
+func keyexprToGo(keyexpr *C.z_loaned_keyexpr_t) string {
+	if keyexpr == nil {
+		return ""
+	}
+	var view C.z_view_string_t
+	C.z_keyexpr_as_view_string(keyexpr, &view)
+	return viewStringToGo(&view)
+}
+
 type CallbackRegistry struct {
 	mu       sync.RWMutex
 	handlers map[uintptr]interface{}
@@ -363,64 +329,119 @@ func (s *Session) OwnedPtr() unsafe.Pointer {
 	return unsafe.Pointer(s.owned)
 }
 
+// Close gracefully closes the session with the default timeout and drops it.
 func (s *Session) Close() error {
-	if s.owned != nil {
-		C.z_session_drop((*C.z_moved_session_t)(unsafe.Pointer(s.owned)))
-		s.owned = nil
-		s.ptr = nil
+	return s.CloseWithTimeout(0)
+}
+
+// CloseWithTimeout gracefully closes the session, waiting at most timeoutMs
+// milliseconds (0 means the zenoh-c default of 10 seconds), then drops it.
+// The session is dropped even if closing fails.
+func (s *Session) CloseWithTimeout(timeoutMs uint32) error {
+	if s.owned == nil {
+		return nil
 	}
-	return nil
+	err := s.Shutdown(timeoutMs)
+	C.z_session_drop((*C.z_moved_session_t)(unsafe.Pointer(s.owned)))
+	s.owned = nil
+	s.ptr = nil
+	s.Ptr = 0
+	return err
+}
+
+// Shutdown closes the session without dropping it. All entities declared on
+// the session are undeclared and further operations on it fail.
+// This is equivalent to z_close() in zenoh-c.
+func (s *Session) Shutdown(timeoutMs uint32) error {
+	if s.ptr == nil {
+		return nil
+	}
+	var opts C.z_close_options_t
+	C.z_close_options_default(&opts)
+	opts.internal_timeout_ms = C.uint32_t(timeoutMs)
+	return Check(C.z_close(s.ptr, &opts))
 }
 
-func (s *Session) DeclareKeyExpr(keyExpr string) (*OwnedKeyExpr, error) {
-	cKeyExpr := C.CString(keyExpr)
-	defer C.free(unsafe.Pointer(cKeyExpr))
+// ZID returns the zenoh ID of the session.
+func (s *Session) ZID() [ZIDSize]byte {
+	zid := C.z_info_zid(s.ptr)
+	return zidToGo(&zid)
+}
 
-	var owned C.z_owned_keyexpr_t
-	err := C.z_keyexpr_from_str(&owned, cKeyExpr)
-	if err != 0 {
-		return nil, Check(err)
-	}
-	loaned := C.z_keyexpr_loan(&owned)
-	return &OwnedKeyExpr{owned: owned, Ptr: uintptr(unsafe.Pointer(loaned))}, nil
+// PeersZID returns the zenoh IDs of the peers the session is connected to.
+func (s *Session) PeersZID() ([][ZIDSize]byte, error) {
+	return s.infoZids(false)
 }
 
-func (s *Session) DeclarePublisherByKeyExpr(keyExpr string) (*Publisher, error) {
-	cKeyExpr := C.CString(keyExpr)
-	defer C.free(unsafe.Pointer(cKeyExpr))
+// RoutersZID returns the zenoh IDs of the routers the session is connected to.
+func (s *Session) RoutersZID() ([][ZIDSize]byte, error) {
+	return s.infoZids(true)
+}
+
+func (s *Session) infoZids(routers bool) ([][ZIDSize]byte, error) {
+	list := (*C.zid_list_t)(C.calloc(1, C.sizeof_zid_list_t))
+	defer func() {
+		C.free(unsafe.Pointer(list.ids))
+		C.free(unsafe.Pointer(list))
+	}()
+
+	if ret := C.infoZids(s.ptr, C.bool(routers), list); ret != 0 {
+		return nil, Check(ret)
+	}
+
+	ids := unsafe.Slice(list.ids, int(list.len))
+	out := make([][ZIDSize]byte, len(ids))
+	for i := range ids {
+		out[i] = zidToGo(&ids[i])
+	}
+	return out, nil
+}
 
+func (s *Session) DeclarePublisherByKeyExpr(keyExpr KeyExprArg) (*Publisher, error) {
 	var ownedKeyExpr C.z_owned_keyexpr_t
-	err := C.z_keyexpr_from_str(&ownedKeyExpr, cKeyExpr)
-	if err != 0 {
-		return nil, Check(err)
+	if err := keyExpr.toOwned(&ownedKeyExpr); err != nil {
+		return nil, err
 	}
 	loanedKeyExpr := C.z_keyexpr_loan(&ownedKeyExpr)
 
 	var owned C.z_owned_publisher_t
-	err = C.z_declare_publisher(s.ptr, &owned, loanedKeyExpr, nil)
+	ret := C.z_declare_publisher(s.ptr, &owned, loanedKeyExpr, nil)
 	C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
-	if err != 0 {
-		return nil, Check(err)
+	if ret != 0 {
+		return nil, Check(ret)
 	}
 	loaned := C.z_publisher_loan(&owned)
 	return &Publisher{ptr: loaned, owned: &owned, Ptr: uintptr(unsafe.Pointer(loaned))}, nil
 }
 
-func (s *Session) DeclarePublisherByKeyExprWithOptions(keyExpr string, reliability int, congestion int) (*Publisher, error) {
-	cKeyExpr := C.CString(keyExpr)
-	defer C.free(unsafe.Pointer(cKeyExpr))
+// PublisherOptions mirrors z_publisher_options_t. A zero Priority keeps the
+// zenoh-c default. AllowedDestination holds a z_locality_t value.
+type PublisherOptions struct {
+	Reliability        int
+	CongestionControl  int
+	Priority           int
+	IsExpress          bool
+	AllowedDestination int
+}
 
+func (s *Session) DeclarePublisherByKeyExprWithOptions(keyExpr KeyExprArg, options *PublisherOptions) (*Publisher, error) {
 	var ownedKeyExpr C.z_owned_keyexpr_t
-	err := C.z_keyexpr_from_str(&ownedKeyExpr, cKeyExpr)
-	if err != 0 {
-		return nil, Check(err)
+	if err := keyExpr.toOwned(&ownedKeyExpr); err != nil {
+		return nil, err
 	}
 	loanedKeyExpr := C.z_keyexpr_loan(&ownedKeyExpr)
 
 	var opts C.z_publisher_options_t
 	C.z_publisher_options_default(&opts)
-	opts.reliability = C.enum_z_reliability_t(reliability)
-	opts.congestion_control = C.enum_z_congestion_control_t(congestion)
+	if options != nil {
+		opts.reliability = C.enum_z_reliability_t(options.Reliability)
+		opts.congestion_control = C.enum_z_congestion_control_t(options.CongestionControl)
+		if options.Priority != 0 {
+			opts.priority = C.enum_z_priority_t(options.Priority)
+		}
+		opts.is_express = C.bool(options.IsExpress)
+		opts.allowed_destination = C.enum_z_locality_t(options.AllowedDestination)
+	}
 
 	var owned C.z_owned_publisher_t
 	ret := C.z_declare_publisher(s.ptr, &owned, loanedKeyExpr, &opts)
@@ -432,9 +453,151 @@ func (s *Session) DeclarePublisherByKeyExprWithOptions(keyExpr string, reliabili
 	return &Publisher{ptr: loaned, owned: &owned, Ptr: uintptr(unsafe.Pointer(loaned))}, nil
 }
 
+// keyexprFromStr constructs an owned key expression from its string form.
+// The caller must drop it with z_keyexpr_drop.
+func keyexprFromStr(owned *C.z_owned_keyexpr_t, keyExpr string) error {
+	cKeyExpr := C.CString(keyExpr)
+	defer C.free(unsafe.Pointer(cKeyExpr))
+	return Check(C.z_keyexpr_from_str(owned, cKeyExpr))
+}
+
+// bytesFromGo copies a Go slice into an owned zenoh bytes object.
+// An empty slice produces empty bytes.
+func bytesFromGo(owned *C.z_owned_bytes_t, data []byte) error {
+	if len(data) == 0 {
+		C.z_bytes_empty(owned)
+		return nil
+	}
+	return Check(C.z_bytes_copy_from_buf(owned, (*C.uint8_t)(unsafe.Pointer(&data[0])), C.size_t(len(data))))
+}
+
+// newOwnedBytes allocates owned zenoh bytes in C memory so that they can be
+// referenced from option structs. The caller must release them with freeOwnedBytes.
+func newOwnedBytes(data []byte) (*C.z_owned_bytes_t, error) {
+	owned := (*C.z_owned_bytes_t)(C.malloc(C.sizeof_z_owned_bytes_t))
+	if err := bytesFromGo(owned, data); err != nil {
+		C.free(unsafe.Pointer(owned))
+		return nil, err
+	}
+	return owned, nil
+}
+
+// freeOwnedBytes drops and frees bytes allocated by newOwnedBytes.
+// Dropping is a no-op once the bytes have been moved into a zenoh-c call.
+func freeOwnedBytes(owned *C.z_owned_bytes_t) {
+	C.z_bytes_drop((*C.z_moved_bytes_t)(unsafe.Pointer(owned)))
+	C.free(unsafe.Pointer(owned))
+}
+
+// PutOptions mirrors z_put_options_t. Zero values keep the zenoh-c defaults.
+// CongestionControl holds a z_congestion_control_t value; nil keeps the
+// zenoh-c default. AllowedDestination holds a z_locality_t value.
+type PutOptions struct {
+	Encoding           *Encoding
+	CongestionControl  *int
+	Priority           int
+	IsExpress          bool
+	AllowedDestination int
+	Attachment         []byte
+	Timestamp          *TimestampData
+}
+
+// DeleteOptions mirrors z_delete_options_t. A zero Priority or a nil
+// CongestionControl keeps the zenoh-c default.
+type DeleteOptions struct {
+	CongestionControl  *int
+	Priority           int
+	IsExpress          bool
+	AllowedDestination int
+	Timestamp          *TimestampData
+}
+
+func (s *Session) Put(keyExpr KeyExprArg, payload []byte, options *PutOptions) error {
+	var ownedKeyExpr C.z_owned_keyexpr_t
+	if err := keyExpr.toOwned(&ownedKeyExpr); err != nil {
+		return err
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
+
+	var opts C.z_put_options_t
+	C.z_put_options_default(&opts)
+	if options != nil {
+		if options.CongestionControl != nil {
+			opts.congestion_control = C.enum_z_congestion_control_t(*options.CongestionControl)
+		}
+		if options.Priority != 0 {
+			opts.priority = C.enum_z_priority_t(options.Priority)
+		}
+		opts.is_express = C.bool(options.IsExpress)
+		opts.allowed_destination = C.enum_z_locality_t(options.AllowedDestination)
+		if options.Encoding != nil {
+			ownedEnc, err := options.Encoding.newOwned()
+			if err != nil {
+				return err
+			}
+			defer freeOwnedEncoding(ownedEnc)
+			opts.encoding = (*C.z_moved_encoding_t)(unsafe.Pointer(ownedEnc))
+		}
+		if options.Attachment != nil {
+			ownedAttachment, err := newOwnedBytes(options.Attachment)
+			if err != nil {
+				return err
+			}
+			defer freeOwnedBytes(ownedAttachment)
+			opts.attachment = (*C.z_moved_bytes_t)(unsafe.Pointer(ownedAttachment))
+		}
+		if options.Timestamp != nil {
+			ts, err := newTimestamp(options.Timestamp)
+			if err != nil {
+				return err
+			}
+			defer C.free(unsafe.Pointer(ts))
+			opts.timestamp = ts
+		}
+	}
+
+	var ownedBytes C.z_owned_bytes_t
+	if err := bytesFromGo(&ownedBytes, payload); err != nil {
+		return err
+	}
+
+	return Check(C.z_put(s.ptr, C.z_keyexpr_loan(&ownedKeyExpr), (*C.z_moved_bytes_t)(unsafe.Pointer(&ownedBytes)), &opts))
+}
+
+func (s *Session) Delete(keyExpr KeyExprArg, options *DeleteOptions) error {
+	var ownedKeyExpr C.z_owned_keyexpr_t
+	if err := keyExpr.toOwned(&ownedKeyExpr); err != nil {
+		return err
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
+
+	var opts C.z_delete_options_t
+	C.z_delete_options_default(&opts)
+	if options != nil {
+		if options.CongestionControl != nil {
+			opts.congestion_control = C.enum_z_congestion_control_t(*options.CongestionControl)
+		}
+		if options.Priority != 0 {
+			opts.priority = C.enum_z_priority_t(options.Priority)
+		}
+		opts.is_express = C.bool(options.IsExpress)
+		opts.allowed_destination = C.enum_z_locality_t(options.AllowedDestination)
+		if options.Timestamp != nil {
+			ts, err := newTimestamp(options.Timestamp)
+			if err != nil {
+				return err
+			}
+			defer C.free(unsafe.Pointer(ts))
+			opts.timestamp = ts
+		}
+	}
+
+	return Check(C.z_delete(s.ptr, C.z_keyexpr_loan(&ownedKeyExpr), &opts))
+}
+
 // KeyExpr
 type OwnedKeyExpr struct {
-	owned C.z_owned_keyexpr_t
+	owned *C.z_owned_keyexpr_t
 	Ptr   uintptr
 }
 
@@ -446,17 +609,172 @@ func OwnedKeyExprFromPtr(ptr uintptr) *OwnedKeyExpr {
 
 func OwnedKeyExprFromOwnedPtr(ptr uintptr, owned unsafe.Pointer) *OwnedKeyExpr {
 	return &OwnedKeyExpr{
-		owned: *(*C.z_owned_keyexpr_t)(owned),
+		owned: (*C.z_owned_keyexpr_t)(owned),
 		Ptr:   ptr,
 	}
 }
 
 func (k *OwnedKeyExpr) OwnedPtr() unsafe.Pointer {
-	return unsafe.Pointer(&k.owned)
+	return unsafe.Pointer(k.owned)
 }
 
 func (k *OwnedKeyExpr) Drop() {
-	C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&k.owned)))
+	if k.owned != nil {
+		C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(k.owned)))
+		k.owned = nil
+		k.Ptr = 0
+	}
+}
+
+// DeclareKeyExpr declares the key expression on the session, which maps it
+// to a numeric ID on the wire. Operations given the returned key expression
+// through KeyExprArg use that ID.
+// This is equivalent to z_declare_keyexpr() in zenoh-c.
+func (s *Session) DeclareKeyExpr(keyExpr string) (*OwnedKeyExpr, error) {
+	var ownedKeyExpr C.z_owned_keyexpr_t
+	if err := keyexprFromStr(&ownedKeyExpr, keyExpr); err != nil {
+		return nil, err
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
+
+	var declared C.z_owned_keyexpr_t
+	if ret := C.z_declare_keyexpr(s.ptr, &declared, C.z_keyexpr_loan(&ownedKeyExpr)); ret != 0 {
+		return nil, Check(ret)
+	}
+	loaned := C.z_keyexpr_loan(&declared)
+	return &OwnedKeyExpr{owned: &declared, Ptr: uintptr(unsafe.Pointer(loaned))}, nil
+}
+
+// UndeclareKeyExpr undeclares a key expression declared on the session with
+// DeclareKeyExpr and drops it.
+// This is equivalent to z_undeclare_keyexpr() in zenoh-c.
+func (s *Session) UndeclareKeyExpr(k *OwnedKeyExpr) error {
+	if k.owned == nil {
+		return nil
+	}
+	owned := k.owned
+	k.owned = nil
+	k.Ptr = 0
+	return Check(C.z_undeclare_keyexpr(s.ptr, (*C.z_moved_keyexpr_t)(unsafe.Pointer(owned))))
+}
+
+// KeyExprArg is the key expression of a session operation. Declared, when
+// set, is a key expression declared with DeclareKeyExpr and is used instead
+// of Expr.
+type KeyExprArg struct {
+	Expr     string
+	Declared *OwnedKeyExpr
+}
+
+// toOwned is like keyexprFromStr, but copies Declared when it is set so that
+// the operation keeps its numeric ID. The caller must drop the result with
+// z_keyexpr_drop.
+func (k KeyExprArg) toOwned(owned *C.z_owned_keyexpr_t) error {
+	if k.Declared != nil {
+		if k.Declared.owned == nil {
+			return errors.New("key expression has been dropped")
+		}
+		C.z_keyexpr_clone(owned, C.z_keyexpr_loan(k.Declared.owned))
+		return nil
+	}
+	return keyexprFromStr(owned, k.Expr)
+}
+
+// withKeyExprs calls fn with loaned key expressions built from a and b,
+// which must be in canon form.
+func withKeyExprs(a, b string, fn func(a, b *C.z_loaned_keyexpr_t)) error {
+	var left, right C.z_owned_keyexpr_t
+	if err := keyexprFromStr(&left, a); err != nil {
+		return err
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&left)))
+	if err := keyexprFromStr(&right, b); err != nil {
+		return err
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&right)))
+	fn(C.z_keyexpr_loan(&left), C.z_keyexpr_loan(&right))
+	return nil
+}
+
+// KeyExprIncludes reports whether every key matched by b is also matched by a.
+// This is equivalent to z_keyexpr_includes() in zenoh-c.
+func KeyExprIncludes(a, b string) (bool, error) {
+	var includes C.bool
+	err := withKeyExprs(a, b, func(a, b *C.z_loaned_keyexpr_t) {
+		includes = C.z_keyexpr_includes(a, b)
+	})
+	return bool(includes), err
+}
+
+// KeyExprIntersects reports whether at least one key is matched by both a and b.
+// This is equivalent to z_keyexpr_intersects() in zenoh-c.
+func KeyExprIntersects(a, b string) (bool, error) {
+	var intersects C.bool
+	err := withKeyExprs(a, b, func(a, b *C.z_loaned_keyexpr_t) {
+		intersects = C.z_keyexpr_intersects(a, b)
+	})
+	return bool(intersects), err
+}
+
+// KeyExprRelationTo returns the relation of a to b as a
+// z_keyexpr_intersection_level_t value.
+// This is equivalent to z_keyexpr_relation_to() in zenoh-c.
+func KeyExprRelationTo(a, b string) (int, error) {
+	var level C.enum_z_keyexpr_intersection_level_t
+	err := withKeyExprs(a, b, func(a, b *C.z_loaned_keyexpr_t) {
+		level = C.z_keyexpr_relation_to(a, b)
+	})
+	return int(level), err
+}
+
+// KeyExprJoin joins a and b with a '/' and canonizes the result.
+// This is equivalent to z_keyexpr_join() in zenoh-c.
+func KeyExprJoin(a, b string) (string, error) {
+	var joined C.z_owned_keyexpr_t
+	var ret C.z_result_t
+	err := withKeyExprs(a, b, func(a, b *C.z_loaned_keyexpr_t) {
+		ret = C.z_keyexpr_join(&joined, a, b)
+	})
+	if err != nil {
+		return "", err
+	}
+	if ret != 0 {
+		return "", Check(ret)
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&joined)))
+	return keyexprToGo(C.z_keyexpr_loan(&joined)), nil
+}
+
+// KeyExprConcat appends suffix to a without inserting a separator.
+// It fails if the result would not be a canon key expression.
+// This is equivalent to z_keyexpr_concat() in zenoh-c.
+func KeyExprConcat(a, suffix string) (string, error) {
+	var left C.z_owned_keyexpr_t
+	if err := keyexprFromStr(&left, a); err != nil {
+		return "", err
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&left)))
+
+	cSuffix := C.CString(suffix)
+	defer C.free(unsafe.Pointer(cSuffix))
+	var concat C.z_owned_keyexpr_t
+	if ret := C.z_keyexpr_concat(&concat, C.z_keyexpr_loan(&left), cSuffix, C.size_t(len(suffix))); ret != 0 {
+		return "", Check(ret)
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&concat)))
+	return keyexprToGo(C.z_keyexpr_loan(&concat)), nil
+}
+
+// KeyExprCanonize returns the canon form of a key expression.
+// This is equivalent to z_keyexpr_canonize() in zenoh-c.
+func KeyExprCanonize(keyExpr string) (string, error) {
+	cKeyExpr := C.CString(keyExpr)
+	defer C.free(unsafe.Pointer(cKeyExpr))
+	length := C.size_t(len(keyExpr))
+	if ret := C.z_keyexpr_canonize(cKeyExpr, &length); ret != 0 {
+		return "", Check(ret)
+	}
+	return C.GoStringN(cKeyExpr, C.int(length)), nil
 }
 
 // Publisher
@@ -477,21 +795,54 @@ func (p *Publisher) Put(payload []byte, encoding *Encoding) error {
 	if len(payload) == 0 {
 		return nil
 	}
+	return p.PutWithOptions(payload, &PublisherPutOptions{Encoding: encoding})
+}
 
-	var ownedBytes C.z_owned_bytes_t
-	cPayload := C.CBytes(payload)
-	defer C.free(cPayload)
-
-	ret := C.z_bytes_copy_from_buf(&ownedBytes, (*C.uint8_t)(cPayload), C.size_t(len(payload)))
-	if ret != 0 {
-		return Check(ret)
-	}
+// PublisherPutOptions mirrors z_publisher_put_options_t.
+type PublisherPutOptions struct {
+	Encoding   *Encoding
+	Attachment []byte
+	Timestamp  *TimestampData
+}
 
+// PutWithOptions publishes payload, which may be empty.
+// This is equivalent to z_publisher_put() in zenoh-c.
+func (p *Publisher) PutWithOptions(payload []byte, options *PublisherPutOptions) error {
 	var opts C.z_publisher_put_options_t
 	C.z_publisher_put_options_default(&opts)
+	if options != nil {
+		if options.Encoding != nil {
+			ownedEnc, err := options.Encoding.newOwned()
+			if err != nil {
+				return err
+			}
+			defer freeOwnedEncoding(ownedEnc)
+			opts.encoding = (*C.z_moved_encoding_t)(unsafe.Pointer(ownedEnc))
+		}
+		if options.Attachment != nil {
+			ownedAttachment, err := newOwnedBytes(options.Attachment)
+			if err != nil {
+				return err
+			}
+			defer freeOwnedBytes(ownedAttachment)
+			opts.attachment = (*C.z_moved_bytes_t)(unsafe.Pointer(ownedAttachment))
+		}
+		if options.Timestamp != nil {
+			ts, err := newTimestamp(options.Timestamp)
+			if err != nil {
+				return err
+			}
+			defer C.free(unsafe.Pointer(ts))
+			opts.timestamp = ts
+		}
+	}
+
+	var ownedBytes C.z_owned_bytes_t
+	if err := bytesFromGo(&ownedBytes, payload); err != nil {
+		return err
+	}
 
-	moveResult := C.z_publisher_put(p.ptr, (*C.z_moved_bytes_t)(unsafe.Pointer(&ownedBytes)), &opts)
-	return Check(moveResult)
+	return Check(C.z_publisher_put(p.ptr, (*C.z_moved_bytes_t)(unsafe.Pointer(&ownedBytes)), &opts))
 }
 
 func (p *Publisher) Delete() error {
@@ -518,94 +869,286 @@ func (p *Publisher) MatchingStatus() (bool, error) {
 	return bool(status.matching), nil
 }
 
-// Subscriber
-type SubscriberCallback func(SampleData)
+// MatchingStatusCallback is called with true when the first matching entity
+// appears and with false when the last one disappears.
+type MatchingStatusCallback func(matching bool)
 
-type SampleData struct {
-	KeyExpr string
-	Payload []byte
+// MatchingDoneCallback is called once the matching listener has been undeclared.
+type MatchingDoneCallback func()
+
+type matchingHandler struct {
+	callback MatchingStatusCallback
+	done     MatchingDoneCallback
 }
 
-var subscriberRegistry = NewCallbackRegistry()
+var matchingRegistry = NewCallbackRegistry()
 
-//export goSubscriberCallback
-func goSubscriberCallback(sample unsafe.Pointer, context unsafe.Pointer) {
-	handle := uintptr(context)
-	cb, ok := subscriberRegistry.Get(handle)
+//export goMatchingStatusCallback
+func goMatchingStatusCallback(matching C.bool, context unsafe.Pointer) {
+	cb, ok := matchingRegistry.Get(uintptr(context))
 	if !ok {
 		return
 	}
-	callback, ok := cb.(SubscriberCallback)
+	if handler, ok := cb.(*matchingHandler); ok {
+		handler.callback(bool(matching))
+	}
+}
+
+// goMatchingStatusDropCallback runs when zenoh-c drops the matching closure,
+// which happens when the listener is undeclared or its session is closed.
+//
+//export goMatchingStatusDropCallback
+func goMatchingStatusDropCallback(context unsafe.Pointer) {
+	handle := uintptr(context)
+	cb, ok := matchingRegistry.Get(handle)
 	if !ok {
 		return
 	}
+	matchingRegistry.Unregister(handle)
+	if handler, ok := cb.(*matchingHandler); ok && handler.done != nil {
+		handler.done()
+	}
+}
+
+// MatchingListener wraps z_owned_matching_listener_t.
+type MatchingListener struct {
+	owned *C.z_owned_matching_listener_t
+	Ptr   uintptr
+}
 
-	var keyExprBuf [256]byte
-	C.keyexprToString(C.z_sample_keyexpr((*C.z_loaned_sample_t)(sample)), (*C.char)(unsafe.Pointer(&keyExprBuf)), C.size_t(256))
-	keyExpr := C.GoStringN((*C.char)(unsafe.Pointer(&keyExprBuf)), C.int(C.strlen((*C.char)(unsafe.Pointer(&keyExprBuf)))))
+func MatchingListenerFromOwnedPtr(owned unsafe.Pointer) *MatchingListener {
+	return &MatchingListener{
+		owned: (*C.z_owned_matching_listener_t)(owned),
+		Ptr:   uintptr(owned),
+	}
+}
 
-	var payloadBuf [4096]byte
-	payloadLen := C.samplePayloadToSlice((*C.z_loaned_sample_t)(sample), (*C.uint8_t)(unsafe.Pointer(&payloadBuf)), C.size_t(4096))
-	payload := C.GoBytes(unsafe.Pointer(&payloadBuf), C.int(payloadLen))
+func (l *MatchingListener) OwnedPtr() unsafe.Pointer {
+	return unsafe.Pointer(l.owned)
+}
 
-	callback(SampleData{
-		KeyExpr: keyExpr,
-		Payload: payload,
-	})
+// newMatchingClosure registers the handler and builds the closure handed to zenoh-c.
+// Once the closure has been passed to zenoh-c, dropping it unregisters the handler.
+func newMatchingClosure(closure *C.z_owned_closure_matching_status_t, callback MatchingStatusCallback, done MatchingDoneCallback) {
+	handle := matchingRegistry.Register(&matchingHandler{callback: callback, done: done})
+	C.createClosureMatchingStatus(closure, unsafe.Pointer(handle))
 }
 
-func (s *Session) DeclareSubscriber(keyExpr string, callback SubscriberCallback) (*Subscriber, error) {
+// DeclareMatchingListener declares a listener notified whenever the publisher
+// gains its first or loses its last matching subscriber.
+// This is equivalent to z_publisher_declare_matching_listener() in zenoh-c.
+func (p *Publisher) DeclareMatchingListener(callback MatchingStatusCallback, done MatchingDoneCallback) (*MatchingListener, error) {
 	if callback == nil {
 		return nil, errors.New("callback cannot be nil")
 	}
 
-	handle := subscriberRegistry.Register(callback)
+	var closure C.z_owned_closure_matching_status_t
+	newMatchingClosure(&closure, callback, done)
 
-	cKeyExpr := C.CString(keyExpr)
-	defer C.free(unsafe.Pointer(cKeyExpr))
+	var owned C.z_owned_matching_listener_t
+	// On failure zenoh-c drops the closure, which unregisters the handler.
+	ret := C.z_publisher_declare_matching_listener(p.ptr, &owned, (*C.z_moved_closure_matching_status_t)(unsafe.Pointer(&closure)))
+	if ret != 0 {
+		return nil, Check(ret)
+	}
+	return &MatchingListener{owned: &owned, Ptr: uintptr(unsafe.Pointer(&owned))}, nil
+}
 
-	var ownedKeyExpr C.z_owned_keyexpr_t
-	err := C.z_keyexpr_from_str(&ownedKeyExpr, cKeyExpr)
-	if err != 0 {
-		subscriberRegistry.Unregister(handle)
-		return nil, Check(err)
+// Undeclare undeclares the matching listener.
+// This is equivalent to z_undeclare_matching_listener() in zenoh-c.
+func (l *MatchingListener) Undeclare() error {
+	if l.owned == nil {
+		return nil
 	}
-	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
+	ret := C.z_undeclare_matching_listener((*C.z_moved_matching_listener_t)(unsafe.Pointer(l.owned)))
+	l.owned = nil
+	l.Ptr = 0
+	return Check(ret)
+}
 
-	loanedKeyExpr := C.z_keyexpr_loan(&ownedKeyExpr)
+// Subscriber
+type SubscriberCallback func(SampleData)
 
-	var closure C.z_owned_closure_sample_t
-	C.createClosureSample(&closure, unsafe.Pointer(handle))
+// SampleData is a Go copy of a loaned sample. Kind, Priority and CongestionControl
+// hold z_sample_kind_t, z_priority_t and z_congestion_control_t values.
+// Timestamp, SourceInfo and Attachment are nil when the sample carries none.
+type SampleData struct {
+	KeyExpr           string
+	Payload           []byte
+	Encoding          string
+	Kind              int
+	Timestamp         *TimestampData
+	Priority          int
+	CongestionControl int
+	Express           bool
+	SourceInfo        *SourceInfoData
+	Attachment        []byte
+}
+
+// TimestampData is a Go copy of z_timestamp_t: an NTP64 time and the ID of the
+// zenoh node whose clock produced it. Raw keeps the z_timestamp_t it was read
+// from, so that the timestamp can be sent back to zenoh-c.
+type TimestampData struct {
+	NTP64 uint64
+	ID    [ZIDSize]byte
+	Raw   RawTimestamp
+}
+
+// RawTimestamp is an opaque z_timestamp_t produced by zenoh-c. Its zero value
+// holds no timestamp.
+type RawTimestamp struct {
+	ts C.z_timestamp_t
+	ok bool
+}
+
+// SourceInfoData is a Go copy of z_source_info_t: the global ID of the entity
+// that issued a sample and the sequence number it gave to it.
+type SourceInfoData struct {
+	ZID [ZIDSize]byte
+	EID uint32
+	SN  uint32
+}
+
+func timestampToGo(ts *C.z_timestamp_t) *TimestampData {
+	if ts == nil {
+		return nil
+	}
+	id := C.z_timestamp_id(ts)
+	return &TimestampData{
+		NTP64: uint64(C.z_timestamp_ntp64_time(ts)),
+		ID:    zidToGo(&id),
+		Raw:   RawTimestamp{ts: *ts, ok: true},
+	}
+}
 
-	var opts C.z_subscriber_options_t
-	C.z_subscriber_options_default(&opts)
+// newTimestamp copies the raw z_timestamp_t of ts to C memory so that it can
+// be referenced from option structs. The caller must release it with C.free.
+//
+// zenoh-c only creates timestamps from a session clock, so ts must have been
+// read from zenoh-c, and its NTP64 and ID must not have been changed since.
+func newTimestamp(ts *TimestampData) (*C.z_timestamp_t, error) {
+	if !ts.Raw.ok {
+		return nil, errors.New("timestamp was not created by zenoh-c")
+	}
+	if got := timestampToGo(&ts.Raw.ts); got.NTP64 != ts.NTP64 || got.ID != ts.ID {
+		return nil, errors.New("timestamp differs from the one created by zenoh-c")
+	}
+	out := (*C.z_timestamp_t)(C.malloc(C.sizeof_z_timestamp_t))
+	*out = ts.Raw.ts
+	return out, nil
+}
 
-	var ownedSubscriber C.z_owned_subscriber_t
-	ret := C.z_declare_subscriber(s.ptr, &ownedSubscriber, loanedKeyExpr, (*C.z_moved_closure_sample_t)(unsafe.Pointer(&closure)), &opts)
-	if ret != 0 {
-		subscriberRegistry.Unregister(handle)
+// NewTimestamp returns a timestamp from the session HLC.
+// This is equivalent to z_timestamp_new() in zenoh-c.
+func (s *Session) NewTimestamp() (*TimestampData, error) {
+	var ts C.z_timestamp_t
+	if ret := C.z_timestamp_new(&ts, s.ptr); ret != 0 {
 		return nil, Check(ret)
 	}
-
-	loaned := C.z_subscriber_loan(&ownedSubscriber)
-	return &Subscriber{ptr: loaned, owned: &ownedSubscriber, Ptr: uintptr(unsafe.Pointer(loaned))}, nil
+	return timestampToGo(&ts), nil
 }
 
-func (s *Session) DeclareSubscriberWithOptions(keyExpr string, callback SubscriberCallback, reliability int) (*Subscriber, error) {
-	if callback == nil {
-		return nil, errors.New("callback cannot be nil")
+func sourceInfoToGo(info *C.z_source_info_t) *SourceInfoData {
+	if info == nil {
+		return nil
 	}
-
+	gid := C.z_source_info_id(info)
+	zid := C.z_entity_global_id_zid(&gid)
+	return &SourceInfoData{
+		ZID: zidToGo(&zid),
+		EID: uint32(C.z_entity_global_id_eid(&gid)),
+		SN:  uint32(C.z_source_info_sn(info)),
+	}
+}
+
+// sampleToGo copies a loaned sample into Go memory.
+func sampleToGo(sample *C.z_loaned_sample_t) SampleData {
+	data := SampleData{
+		KeyExpr:           keyexprToGo(C.z_sample_keyexpr(sample)),
+		Payload:           bytesToGo(C.z_sample_payload(sample)),
+		Encoding:          encodingToString(C.z_sample_encoding(sample)),
+		Kind:              int(C.z_sample_kind(sample)),
+		Timestamp:         timestampToGo(C.z_sample_timestamp(sample)),
+		Priority:          int(C.z_sample_priority(sample)),
+		CongestionControl: int(C.z_sample_congestion_control(sample)),
+		Express:           bool(C.z_sample_express(sample)),
+		SourceInfo:        sourceInfoToGo(C.z_sample_source_info(sample)),
+	}
+	if attachment := C.z_sample_attachment(sample); attachment != nil {
+		data.Attachment = bytesToGo(attachment)
+	}
+	return data
+}
+
+var subscriberRegistry = NewCallbackRegistry()
+
+//export goSubscriberCallback
+func goSubscriberCallback(sample unsafe.Pointer, context unsafe.Pointer) {
+	handle := uintptr(context)
+	cb, ok := subscriberRegistry.Get(handle)
+	if !ok {
+		return
+	}
+	callback, ok := cb.(SubscriberCallback)
+	if !ok {
+		return
+	}
+
+	callback(sampleToGo((*C.z_loaned_sample_t)(sample)))
+}
+
+// goSubscriberDropCallback runs when zenoh-c drops the sample closure, which
+// happens when the subscriber is undeclared or its session is closed.
+//
+//export goSubscriberDropCallback
+func goSubscriberDropCallback(context unsafe.Pointer) {
+	subscriberRegistry.Unregister(uintptr(context))
+}
+
+func (s *Session) DeclareSubscriber(keyExpr KeyExprArg, callback SubscriberCallback) (*Subscriber, error) {
+	if callback == nil {
+		return nil, errors.New("callback cannot be nil")
+	}
+
 	handle := subscriberRegistry.Register(callback)
 
-	cKeyExpr := C.CString(keyExpr)
-	defer C.free(unsafe.Pointer(cKeyExpr))
+	var ownedKeyExpr C.z_owned_keyexpr_t
+	if err := keyExpr.toOwned(&ownedKeyExpr); err != nil {
+		subscriberRegistry.Unregister(handle)
+		return nil, err
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
+
+	loanedKeyExpr := C.z_keyexpr_loan(&ownedKeyExpr)
+
+	var closure C.z_owned_closure_sample_t
+	C.createClosureSample(&closure, unsafe.Pointer(handle))
+
+	var opts C.z_subscriber_options_t
+	C.z_subscriber_options_default(&opts)
+
+	var ownedSubscriber C.z_owned_subscriber_t
+	ret := C.z_declare_subscriber(s.ptr, &ownedSubscriber, loanedKeyExpr, (*C.z_moved_closure_sample_t)(unsafe.Pointer(&closure)), &opts)
+	if ret != 0 {
+		subscriberRegistry.Unregister(handle)
+		return nil, Check(ret)
+	}
+
+	loaned := C.z_subscriber_loan(&ownedSubscriber)
+	return &Subscriber{ptr: loaned, owned: &ownedSubscriber, Ptr: uintptr(unsafe.Pointer(loaned))}, nil
+}
+
+func (s *Session) DeclareSubscriberWithOptions(keyExpr KeyExprArg, callback SubscriberCallback, reliability int) (*Subscriber, error) {
+	if callback == nil {
+		return nil, errors.New("callback cannot be nil")
+	}
+
+	handle := subscriberRegistry.Register(callback)
 
 	var ownedKeyExpr C.z_owned_keyexpr_t
-	err := C.z_keyexpr_from_str(&ownedKeyExpr, cKeyExpr)
-	if err != 0 {
+	if err := keyExpr.toOwned(&ownedKeyExpr); err != nil {
 		subscriberRegistry.Unregister(handle)
-		return nil, Check(err)
+		return nil, err
 	}
 	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
 
@@ -634,6 +1177,18 @@ type Subscriber struct {
 	Ptr   uintptr
 }
 
+func SubscriberFromOwnedPtr(ptr uintptr, owned unsafe.Pointer) *Subscriber {
+	return &Subscriber{
+		ptr:   (*C.z_loaned_subscriber_t)(unsafe.Pointer(ptr)),
+		owned: (*C.z_owned_subscriber_t)(owned),
+		Ptr:   ptr,
+	}
+}
+
+func (s *Subscriber) OwnedPtr() unsafe.Pointer {
+	return unsafe.Pointer(s.owned)
+}
+
 func (s *Subscriber) Undeclare() error {
 	if s.owned != nil {
 		ret := C.z_undeclare_subscriber((*C.z_moved_subscriber_t)(unsafe.Pointer(s.owned)))
@@ -646,19 +1201,186 @@ func (s *Subscriber) Undeclare() error {
 	return nil
 }
 
-// Encoding (simplified - just a placeholder)
+// LivelinessToken wraps z_owned_liveliness_token_t. Subscribers on an intersecting
+// key expression see a PUT sample when it is declared and a DELETE sample when
+// it is undeclared or its session disappears.
+type LivelinessToken struct {
+	ptr   *C.z_loaned_liveliness_token_t
+	owned *C.z_owned_liveliness_token_t
+	Ptr   uintptr
+}
+
+func LivelinessTokenFromOwnedPtr(ptr uintptr, owned unsafe.Pointer) *LivelinessToken {
+	return &LivelinessToken{
+		ptr:   (*C.z_loaned_liveliness_token_t)(unsafe.Pointer(ptr)),
+		owned: (*C.z_owned_liveliness_token_t)(owned),
+		Ptr:   ptr,
+	}
+}
+
+func (t *LivelinessToken) OwnedPtr() unsafe.Pointer {
+	return unsafe.Pointer(t.owned)
+}
+
+// DeclareLivelinessToken declares a liveliness token on the given key expression.
+// This is equivalent to z_liveliness_declare_token() in zenoh-c.
+func (s *Session) DeclareLivelinessToken(keyExpr KeyExprArg) (*LivelinessToken, error) {
+	var ownedKeyExpr C.z_owned_keyexpr_t
+	if err := keyExpr.toOwned(&ownedKeyExpr); err != nil {
+		return nil, err
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
+
+	var opts C.z_liveliness_token_options_t
+	C.z_liveliness_token_options_default(&opts)
+
+	var ownedToken C.z_owned_liveliness_token_t
+	ret := C.z_liveliness_declare_token(s.ptr, &ownedToken, C.z_keyexpr_loan(&ownedKeyExpr), &opts)
+	if ret != 0 {
+		return nil, Check(ret)
+	}
+
+	loaned := C.z_liveliness_token_loan(&ownedToken)
+	return &LivelinessToken{ptr: loaned, owned: &ownedToken, Ptr: uintptr(unsafe.Pointer(loaned))}, nil
+}
+
+// Undeclare undeclares the liveliness token.
+// This is equivalent to z_liveliness_undeclare_token() in zenoh-c.
+func (t *LivelinessToken) Undeclare() error {
+	if t.owned == nil {
+		return nil
+	}
+	ret := C.z_liveliness_undeclare_token((*C.z_moved_liveliness_token_t)(unsafe.Pointer(t.owned)))
+	t.owned = nil
+	t.ptr = nil
+	t.Ptr = 0
+	return Check(ret)
+}
+
+// DeclareLivelinessSubscriber declares a subscriber on the liveliness tokens
+// intersecting keyExpr. With history set, tokens declared before the subscriber
+// are reported as well.
+// This is equivalent to z_liveliness_declare_subscriber() in zenoh-c.
+func (s *Session) DeclareLivelinessSubscriber(keyExpr KeyExprArg, callback SubscriberCallback, history bool) (*Subscriber, error) {
+	if callback == nil {
+		return nil, errors.New("callback cannot be nil")
+	}
+
+	var ownedKeyExpr C.z_owned_keyexpr_t
+	if err := keyExpr.toOwned(&ownedKeyExpr); err != nil {
+		return nil, err
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
+
+	handle := subscriberRegistry.Register(callback)
+
+	var closure C.z_owned_closure_sample_t
+	C.createClosureSample(&closure, unsafe.Pointer(handle))
+
+	var opts C.z_liveliness_subscriber_options_t
+	C.z_liveliness_subscriber_options_default(&opts)
+	opts.history = C.bool(history)
+
+	var ownedSubscriber C.z_owned_subscriber_t
+	ret := C.z_liveliness_declare_subscriber(s.ptr, &ownedSubscriber, C.z_keyexpr_loan(&ownedKeyExpr), (*C.z_moved_closure_sample_t)(unsafe.Pointer(&closure)), &opts)
+	if ret != 0 {
+		subscriberRegistry.Unregister(handle)
+		return nil, Check(ret)
+	}
+
+	loaned := C.z_subscriber_loan(&ownedSubscriber)
+	return &Subscriber{ptr: loaned, owned: &ownedSubscriber, Ptr: uintptr(unsafe.Pointer(loaned))}, nil
+}
+
+// LivelinessGet queries the liveliness tokens currently alive on keyExpr. Each
+// alive token is reported as an Ok reply; done is called once the query finishes.
+// A zero timeoutMs keeps the zenoh-c default.
+// This is equivalent to z_liveliness_get() in zenoh-c.
+func (s *Session) LivelinessGet(keyExpr KeyExprArg, callback QueryReplyCallback, done QueryDoneCallback, timeoutMs uint64) error {
+	if callback == nil {
+		return errors.New("callback cannot be nil")
+	}
+
+	var ownedKeyExpr C.z_owned_keyexpr_t
+	if err := keyExpr.toOwned(&ownedKeyExpr); err != nil {
+		return err
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
+
+	var opts C.z_liveliness_get_options_t
+	C.z_liveliness_get_options_default(&opts)
+	if timeoutMs > 0 {
+		opts.timeout_ms = C.uint64_t(timeoutMs)
+	}
+
+	handle := replyRegistry.Register(&replyHandler{callback: callback, done: done})
+
+	var closure C.z_owned_closure_reply_t
+	C.createClosureReply(&closure, unsafe.Pointer(handle))
+
+	// On failure zenoh-c drops the closure, which unregisters the handle.
+	return Check(C.z_liveliness_get(s.ptr, C.z_keyexpr_loan(&ownedKeyExpr), (*C.z_moved_closure_reply_t)(unsafe.Pointer(&closure)), &opts))
+}
+
+// Encoding carries a zenoh encoding across the cgo boundary in its string form,
+// as produced by z_encoding_to_string and accepted by z_encoding_from_str.
 type Encoding struct {
-	ptr *C.z_loaned_encoding_t
+	Value string
+}
+
+// newOwned builds an owned zenoh-c encoding in C memory so that it can be
+// referenced from option structs. The caller must release it with freeOwnedEncoding.
+func (e *Encoding) newOwned() (*C.z_owned_encoding_t, error) {
+	owned := (*C.z_owned_encoding_t)(C.malloc(C.sizeof_z_owned_encoding_t))
+	cValue := C.CString(e.Value)
+	defer C.free(unsafe.Pointer(cValue))
+	if ret := C.z_encoding_from_str(owned, cValue); ret != 0 {
+		C.free(unsafe.Pointer(owned))
+		return nil, Check(ret)
+	}
+	return owned, nil
+}
+
+// freeOwnedEncoding drops and frees an encoding allocated by Encoding.newOwned.
+// Dropping is a no-op once the encoding has been moved into a zenoh-c call.
+func freeOwnedEncoding(owned *C.z_owned_encoding_t) {
+	C.z_encoding_drop((*C.z_moved_encoding_t)(unsafe.Pointer(owned)))
+	C.free(unsafe.Pointer(owned))
+}
+
+// encodingToString returns the string form of a loaned encoding.
+func encodingToString(enc *C.z_loaned_encoding_t) string {
+	if enc == nil {
+		return ""
+	}
+	var str C.z_owned_string_t
+	C.z_encoding_to_string(enc, &str)
+	defer C.z_string_drop((*C.z_moved_string_t)(unsafe.Pointer(&str)))
+	loaned := C.z_string_loan(&str)
+	return C.GoStringN(C.z_string_data(loaned), C.int(C.z_string_len(loaned)))
 }
 
 // Query Reply types
 type QueryReplyCallback func(QueryReplyData)
 
+// QueryReplyData is a Go copy of a reply. Attachment is nil when the reply carries none.
+// For an error reply, Payload and Encoding hold the error body.
 type QueryReplyData struct {
-	Ok      bool
-	KeyExpr string
-	Payload []byte
-	ErrMsg  string
+	Ok         bool
+	Kind       int
+	KeyExpr    string
+	Payload    []byte
+	Encoding   string
+	Attachment []byte
+	ErrMsg     string
+}
+
+// QueryDoneCallback is called once no more replies will be delivered for a query.
+type QueryDoneCallback func()
+
+type replyHandler struct {
+	callback QueryReplyCallback
+	done     QueryDoneCallback
 }
 
 var replyRegistry = NewCallbackRegistry()
@@ -670,159 +1392,553 @@ func goReplyCallback(reply unsafe.Pointer, context unsafe.Pointer) {
 	if !ok {
 		return
 	}
-	callback, ok := cb.(QueryReplyCallback)
+	handler, ok := cb.(*replyHandler)
 	if !ok {
 		return
 	}
 
-	isOk := C.z_reply_is_ok((*C.z_loaned_reply_t)(reply))
+	loaned := (*C.z_loaned_reply_t)(reply)
 
 	var data QueryReplyData
-	if isOk {
-		var keyExprBuf [256]byte
-		C.replyOkKeyexprToString((*C.z_loaned_reply_t)(reply), (*C.char)(unsafe.Pointer(&keyExprBuf)), C.size_t(256))
-		keyExpr := C.GoStringN((*C.char)(unsafe.Pointer(&keyExprBuf)), C.int(C.strlen((*C.char)(unsafe.Pointer(&keyExprBuf)))))
-
-		var payloadBuf [4096]byte
-		payloadLen := C.replyOkPayloadToSlice((*C.z_loaned_reply_t)(reply), (*C.uint8_t)(unsafe.Pointer(&payloadBuf)), C.size_t(4096))
-		payload := C.GoBytes(unsafe.Pointer(&payloadBuf), C.int(payloadLen))
-
+	if C.z_reply_is_ok(loaned) {
+		sample := C.z_reply_ok(loaned)
 		data = QueryReplyData{
-			Ok:      true,
-			KeyExpr: keyExpr,
-			Payload: payload,
+			Ok:       true,
+			Kind:     int(C.z_sample_kind(sample)),
+			KeyExpr:  keyexprToGo(C.z_sample_keyexpr(sample)),
+			Payload:  bytesToGo(C.z_sample_payload(sample)),
+			Encoding: encodingToString(C.z_sample_encoding(sample)),
+		}
+		if attachment := C.z_sample_attachment(sample); attachment != nil {
+			data.Attachment = bytesToGo(attachment)
 		}
 	} else {
-		replyErr := C.z_reply_err((*C.z_loaned_reply_t)(reply))
-		if replyErr != nil {
-			errPayload := C.z_reply_err_payload(replyErr)
-			if errPayload != nil {
-				var errSlice C.z_owned_slice_t
-				C.z_bytes_to_slice(errPayload, &errSlice)
-				errLoaned := C.z_slice_loan(&errSlice)
-				errLen := C.z_slice_len(errLoaned)
-				if errLen > 0 {
-					errData := C.z_slice_data(errLoaned)
-					data = QueryReplyData{
-						Ok:     false,
-						ErrMsg: C.GoStringN((*C.char)(unsafe.Pointer(errData)), C.int(errLen)),
-					}
-				} else {
-					data = QueryReplyData{
-						Ok:     false,
-						ErrMsg: "unknown error",
-					}
-				}
-				C.z_slice_drop((*C.z_moved_slice_t)(unsafe.Pointer(&errSlice)))
-			} else {
-				data = QueryReplyData{
-					Ok:     false,
-					ErrMsg: "unknown error",
-				}
-			}
-		} else {
-			data = QueryReplyData{
-				Ok:     false,
-				ErrMsg: "unknown error",
+		data = QueryReplyData{
+			Ok:     false,
+			ErrMsg: "unknown error",
+		}
+		if replyErr := C.z_reply_err(loaned); replyErr != nil {
+			data.Payload = bytesToGo(C.z_reply_err_payload(replyErr))
+			data.Encoding = encodingToString(C.z_reply_err_encoding(replyErr))
+			if len(data.Payload) > 0 {
+				data.ErrMsg = string(data.Payload)
 			}
 		}
 	}
 
-	C.z_reply_drop((*C.z_moved_reply_t)(unsafe.Pointer(reply)))
+	handler.callback(data)
+}
 
-	callback(data)
+// goReplyDropCallback runs when zenoh-c drops the reply closure, which happens
+// once the query has finished: all replies were received or the timeout expired.
+//
+//export goReplyDropCallback
+func goReplyDropCallback(context unsafe.Pointer) {
+	handle := uintptr(context)
+	cb, ok := replyRegistry.Get(handle)
+	if !ok {
+		return
+	}
+	replyRegistry.Unregister(handle)
+	if handler, ok := cb.(*replyHandler); ok && handler.done != nil {
+		handler.done()
+	}
+}
+
+// CancellationToken wraps z_owned_cancellation_token_t. Cancelling it interrupts
+// every GET query it was passed to.
+type CancellationToken struct {
+	owned *C.z_owned_cancellation_token_t
+}
+
+func NewCancellationToken() (*CancellationToken, error) {
+	owned := (*C.z_owned_cancellation_token_t)(C.malloc(C.sizeof_z_owned_cancellation_token_t))
+	if ret := C.z_cancellation_token_new(owned); ret != 0 {
+		C.free(unsafe.Pointer(owned))
+		return nil, Check(ret)
+	}
+	return &CancellationToken{owned: owned}, nil
+}
+
+// Cancel interrupts the associated queries. If a reply callback is running,
+// Cancel blocks until it returns.
+func (t *CancellationToken) Cancel() error {
+	if t.owned == nil {
+		return nil
+	}
+	return Check(C.z_cancellation_token_cancel(C.z_cancellation_token_loan_mut(t.owned)))
+}
+
+func (t *CancellationToken) Drop() {
+	if t.owned != nil {
+		C.z_cancellation_token_drop((*C.z_moved_cancellation_token_t)(unsafe.Pointer(t.owned)))
+		C.free(unsafe.Pointer(t.owned))
+		t.owned = nil
+	}
+}
+
+// GetOptions mirrors z_get_options_t. Consolidation holds a z_consolidation_mode_t
+// value and a zero TimeoutMs keeps the zenoh-c default.
+type GetOptions struct {
+	Target            int
+	Consolidation     int
+	TimeoutMs         uint64
+	Payload           []byte
+	Encoding          *Encoding
+	Attachment        []byte
+	CancellationToken *CancellationToken
 }
 
 func (s *Session) Get(keyExpr string, callback QueryReplyCallback) error {
+	return s.GetWithOptions(KeyExprArg{Expr: keyExpr}, "", callback, nil, nil)
+}
+
+// GetWithOptions sends a query. The done callback, if any, is called exactly once
+// when the query finishes, including when it could not be sent.
+func (s *Session) GetWithOptions(keyExpr KeyExprArg, parameters string, callback QueryReplyCallback, done QueryDoneCallback, options *GetOptions) error {
 	if callback == nil {
-		return errors.New("callback cannot be nil")
+		return queryNotSent(done, errors.New("callback cannot be nil"))
 	}
 
-	handle := replyRegistry.Register(callback)
+	var ownedKeyExpr C.z_owned_keyexpr_t
+	if err := keyExpr.toOwned(&ownedKeyExpr); err != nil {
+		return queryNotSent(done, err)
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
 
-	cKeyExpr := C.CString(keyExpr)
-	defer C.free(unsafe.Pointer(cKeyExpr))
+	var cParams *C.char
+	if parameters != "" {
+		cParams = C.CString(parameters)
+		defer C.free(unsafe.Pointer(cParams))
+	}
+
+	var opts C.z_get_options_t
+	C.z_get_options_default(&opts)
+	if options != nil {
+		opts.target = C.enum_z_query_target_t(options.Target)
+		opts.consolidation.mode = C.enum_z_consolidation_mode_t(options.Consolidation)
+		if options.TimeoutMs > 0 {
+			opts.timeout_ms = C.uint64_t(options.TimeoutMs)
+		}
+		if options.Payload != nil {
+			ownedPayload, err := newOwnedBytes(options.Payload)
+			if err != nil {
+				return queryNotSent(done, err)
+			}
+			defer freeOwnedBytes(ownedPayload)
+			opts.payload = (*C.z_moved_bytes_t)(unsafe.Pointer(ownedPayload))
+		}
+		if options.Encoding != nil {
+			ownedEnc, err := options.Encoding.newOwned()
+			if err != nil {
+				return queryNotSent(done, err)
+			}
+			defer freeOwnedEncoding(ownedEnc)
+			opts.encoding = (*C.z_moved_encoding_t)(unsafe.Pointer(ownedEnc))
+		}
+		if options.Attachment != nil {
+			ownedAttachment, err := newOwnedBytes(options.Attachment)
+			if err != nil {
+				return queryNotSent(done, err)
+			}
+			defer freeOwnedBytes(ownedAttachment)
+			opts.attachment = (*C.z_moved_bytes_t)(unsafe.Pointer(ownedAttachment))
+		}
+		if options.CancellationToken != nil && options.CancellationToken.owned != nil {
+			clone := &CancellationToken{owned: (*C.z_owned_cancellation_token_t)(C.malloc(C.sizeof_z_owned_cancellation_token_t))}
+			C.z_cancellation_token_clone(clone.owned, C.z_cancellation_token_loan(options.CancellationToken.owned))
+			defer clone.Drop()
+			opts.cancellation_token = (*C.z_moved_cancellation_token_t)(unsafe.Pointer(clone.owned))
+		}
+	}
+
+	handle := replyRegistry.Register(&replyHandler{callback: callback, done: done})
+
+	var closure C.z_owned_closure_reply_t
+	C.createClosureReply(&closure, unsafe.Pointer(handle))
 
+	// On failure zenoh-c drops the closure, which unregisters the handle.
+	return Check(C.z_get(s.ptr, C.z_keyexpr_loan(&ownedKeyExpr), cParams, (*C.z_moved_closure_reply_t)(unsafe.Pointer(&closure)), &opts))
+}
+
+// queryNotSent calls done, if any, for a query that failed before its reply
+// closure was handed to zenoh-c, and returns err.
+func queryNotSent(done QueryDoneCallback, err error) error {
+	if done != nil {
+		done()
+	}
+	return err
+}
+
+// QuerierOptions mirrors z_querier_options_t. Consolidation holds a
+// z_consolidation_mode_t value; a zero Priority or TimeoutMs keeps the zenoh-c default.
+type QuerierOptions struct {
+	Target             int
+	Consolidation      int
+	TimeoutMs          uint64
+	CongestionControl  int
+	Priority           int
+	IsExpress          bool
+	AllowedDestination int
+}
+
+// Querier wraps z_owned_querier_t.
+type Querier struct {
+	ptr   *C.z_loaned_querier_t
+	owned *C.z_owned_querier_t
+	Ptr   uintptr
+}
+
+func QuerierFromOwnedPtr(ptr uintptr, owned unsafe.Pointer) *Querier {
+	return &Querier{
+		ptr:   (*C.z_loaned_querier_t)(unsafe.Pointer(ptr)),
+		owned: (*C.z_owned_querier_t)(owned),
+		Ptr:   ptr,
+	}
+}
+
+func (q *Querier) OwnedPtr() unsafe.Pointer {
+	return unsafe.Pointer(q.owned)
+}
+
+// DeclareQuerier declares a querier on the key expression.
+// This is equivalent to z_declare_querier() in zenoh-c.
+func (s *Session) DeclareQuerier(keyExpr KeyExprArg, options *QuerierOptions) (*Querier, error) {
 	var ownedKeyExpr C.z_owned_keyexpr_t
-	err := C.z_keyexpr_from_str(&ownedKeyExpr, cKeyExpr)
-	if err != 0 {
-		replyRegistry.Unregister(handle)
-		return Check(err)
+	if err := keyExpr.toOwned(&ownedKeyExpr); err != nil {
+		return nil, err
 	}
 	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
 
-	loanedKeyExpr := C.z_keyexpr_loan(&ownedKeyExpr)
+	var opts C.z_querier_options_t
+	C.z_querier_options_default(&opts)
+	if options != nil {
+		opts.target = C.enum_z_query_target_t(options.Target)
+		opts.consolidation.mode = C.enum_z_consolidation_mode_t(options.Consolidation)
+		if options.TimeoutMs > 0 {
+			opts.timeout_ms = C.uint64_t(options.TimeoutMs)
+		}
+		opts.congestion_control = C.enum_z_congestion_control_t(options.CongestionControl)
+		if options.Priority != 0 {
+			opts.priority = C.enum_z_priority_t(options.Priority)
+		}
+		opts.is_express = C.bool(options.IsExpress)
+		opts.allowed_destination = C.enum_z_locality_t(options.AllowedDestination)
+	}
+
+	var owned C.z_owned_querier_t
+	if ret := C.z_declare_querier(s.ptr, &owned, C.z_keyexpr_loan(&ownedKeyExpr), &opts); ret != 0 {
+		return nil, Check(ret)
+	}
+	loaned := C.z_querier_loan(&owned)
+	return &Querier{ptr: loaned, owned: &owned, Ptr: uintptr(unsafe.Pointer(loaned))}, nil
+}
+
+// QuerierGetOptions mirrors z_querier_get_options_t.
+type QuerierGetOptions struct {
+	Payload    []byte
+	Encoding   *Encoding
+	Attachment []byte
+}
+
+// Get sends a query through the querier. The done callback, if any, is called
+// exactly once when the query finishes, including when it could not be sent.
+// This is equivalent to z_querier_get() in zenoh-c.
+func (q *Querier) Get(parameters string, callback QueryReplyCallback, done QueryDoneCallback, options *QuerierGetOptions) error {
+	if callback == nil {
+		return queryNotSent(done, errors.New("callback cannot be nil"))
+	}
+
+	var cParams *C.char
+	if parameters != "" {
+		cParams = C.CString(parameters)
+		defer C.free(unsafe.Pointer(cParams))
+	}
+
+	var opts C.z_querier_get_options_t
+	C.z_querier_get_options_default(&opts)
+	if options != nil {
+		if options.Payload != nil {
+			ownedPayload, err := newOwnedBytes(options.Payload)
+			if err != nil {
+				return queryNotSent(done, err)
+			}
+			defer freeOwnedBytes(ownedPayload)
+			opts.payload = (*C.z_moved_bytes_t)(unsafe.Pointer(ownedPayload))
+		}
+		if options.Encoding != nil {
+			ownedEnc, err := options.Encoding.newOwned()
+			if err != nil {
+				return queryNotSent(done, err)
+			}
+			defer freeOwnedEncoding(ownedEnc)
+			opts.encoding = (*C.z_moved_encoding_t)(unsafe.Pointer(ownedEnc))
+		}
+		if options.Attachment != nil {
+			ownedAttachment, err := newOwnedBytes(options.Attachment)
+			if err != nil {
+				return queryNotSent(done, err)
+			}
+			defer freeOwnedBytes(ownedAttachment)
+			opts.attachment = (*C.z_moved_bytes_t)(unsafe.Pointer(ownedAttachment))
+		}
+	}
+
+	handle := replyRegistry.Register(&replyHandler{callback: callback, done: done})
 
 	var closure C.z_owned_closure_reply_t
 	C.createClosureReply(&closure, unsafe.Pointer(handle))
 
-	var opts C.z_get_options_t
-	C.z_get_options_default(&opts)
+	// On failure zenoh-c drops the closure, which unregisters the handle.
+	return Check(C.z_querier_get(q.ptr, cParams, (*C.z_moved_closure_reply_t)(unsafe.Pointer(&closure)), &opts))
+}
+
+// MatchingStatus reports whether any queryable currently matches the querier.
+// This is equivalent to z_querier_get_matching_status() in zenoh-c.
+func (q *Querier) MatchingStatus() (bool, error) {
+	var status C.z_matching_status_t
+	if ret := C.z_querier_get_matching_status(q.ptr, &status); ret != 0 {
+		return false, Check(ret)
+	}
+	return bool(status.matching), nil
+}
 
-	ret := C.z_get(s.ptr, loanedKeyExpr, nil, (*C.z_moved_closure_reply_t)(unsafe.Pointer(&closure)), &opts)
+// DeclareMatchingListener declares a listener notified whenever the querier
+// gains its first or loses its last matching queryable.
+// This is equivalent to z_querier_declare_matching_listener() in zenoh-c.
+func (q *Querier) DeclareMatchingListener(callback MatchingStatusCallback, done MatchingDoneCallback) (*MatchingListener, error) {
+	if callback == nil {
+		return nil, errors.New("callback cannot be nil")
+	}
+
+	var closure C.z_owned_closure_matching_status_t
+	newMatchingClosure(&closure, callback, done)
+
+	var owned C.z_owned_matching_listener_t
+	// On failure zenoh-c drops the closure, which unregisters the handler.
+	ret := C.z_querier_declare_matching_listener(q.ptr, &owned, (*C.z_moved_closure_matching_status_t)(unsafe.Pointer(&closure)))
 	if ret != 0 {
-		replyRegistry.Unregister(handle)
-		return Check(ret)
+		return nil, Check(ret)
 	}
+	return &MatchingListener{owned: &owned, Ptr: uintptr(unsafe.Pointer(&owned))}, nil
+}
 
-	return nil
+// Undeclare undeclares the querier.
+// This is equivalent to z_undeclare_querier() in zenoh-c.
+func (q *Querier) Undeclare() error {
+	if q.owned == nil {
+		return nil
+	}
+	ret := C.z_undeclare_querier((*C.z_moved_querier_t)(unsafe.Pointer(q.owned)))
+	q.owned = nil
+	q.ptr = nil
+	q.Ptr = 0
+	return Check(ret)
 }
 
 // Query types
+//
+// Payload and Attachment are nil and Encoding is empty when the query carries
+// none. A Query handed to a QueryableCallback is loaned and only valid until
+// the callback returns; use Clone to keep it longer.
 type Query struct {
 	ptr        *C.z_loaned_query_t
+	owned      *C.z_owned_query_t
 	KeyExpr    string
 	Parameters string
 	Payload    []byte
+	Encoding   string
+	Attachment []byte
 }
 
-func (q *Query) Reply(keyExpr string, payload []byte) error {
-	cKeyExpr := C.CString(keyExpr)
-	defer C.free(unsafe.Pointer(cKeyExpr))
+// errQueryReleased is returned when a query is used after it was dropped or,
+// for a loaned query, after its callback returned.
+var errQueryReleased = errors.New("query is not valid")
 
+// Clone returns an owned copy of the query that stays valid after the
+// query callback returns. It must be dropped once the last reply is sent.
+// This is equivalent to z_query_clone() in zenoh-c.
+func (q *Query) Clone() (*Query, error) {
+	if q.ptr == nil {
+		return nil, errQueryReleased
+	}
+	var owned C.z_owned_query_t
+	C.z_query_clone(&owned, q.ptr)
+	clone := *q
+	clone.owned = &owned
+	clone.ptr = C.z_query_loan(&owned)
+	return &clone, nil
+}
+
+// Drop releases an owned query, which tells the querier that no more replies
+// will follow. It is a no-op on a loaned query.
+// This is equivalent to z_query_drop() in zenoh-c.
+func (q *Query) Drop() {
+	if q.owned != nil {
+		C.z_query_drop((*C.z_moved_query_t)(unsafe.Pointer(q.owned)))
+		q.owned = nil
+		q.ptr = nil
+	}
+}
+
+func (q *Query) Reply(keyExpr string, payload []byte, encoding *Encoding) error {
+	return q.ReplyWithOptions(keyExpr, payload, &ReplyOptions{Encoding: encoding})
+}
+
+// ReplyOptions mirrors z_query_reply_options_t. A zero Priority keeps the zenoh-c default.
+type ReplyOptions struct {
+	Encoding          *Encoding
+	CongestionControl int
+	Priority          int
+	IsExpress         bool
+	Attachment        []byte
+	Timestamp         *TimestampData
+}
+
+// ReplyWithOptions sends a reply to the query.
+// This is equivalent to z_query_reply() in zenoh-c.
+func (q *Query) ReplyWithOptions(keyExpr string, payload []byte, options *ReplyOptions) error {
+	if q.ptr == nil {
+		return errQueryReleased
+	}
 	var ownedKeyExpr C.z_owned_keyexpr_t
-	err := C.z_keyexpr_from_str(&ownedKeyExpr, cKeyExpr)
-	if err != 0 {
-		return Check(err)
+	if err := keyexprFromStr(&ownedKeyExpr, keyExpr); err != nil {
+		return err
 	}
-	loanedKeyExpr := C.z_keyexpr_loan(&ownedKeyExpr)
 	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
 
-	var ownedBytes C.z_owned_bytes_t
-	if len(payload) > 0 {
-		cPayload := C.CBytes(payload)
-		defer C.free(cPayload)
-		ret := C.z_bytes_copy_from_buf(&ownedBytes, (*C.uint8_t)(cPayload), C.size_t(len(payload)))
-		if ret != 0 {
-			return Check(ret)
+	var opts C.z_query_reply_options_t
+	C.z_query_reply_options_default(&opts)
+	if options != nil {
+		if options.Encoding != nil {
+			ownedEnc, err := options.Encoding.newOwned()
+			if err != nil {
+				return err
+			}
+			defer freeOwnedEncoding(ownedEnc)
+			opts.encoding = (*C.z_moved_encoding_t)(unsafe.Pointer(ownedEnc))
+		}
+		opts.congestion_control = C.enum_z_congestion_control_t(options.CongestionControl)
+		if options.Priority != 0 {
+			opts.priority = C.enum_z_priority_t(options.Priority)
+		}
+		opts.is_express = C.bool(options.IsExpress)
+		if options.Attachment != nil {
+			ownedAttachment, err := newOwnedBytes(options.Attachment)
+			if err != nil {
+				return err
+			}
+			defer freeOwnedBytes(ownedAttachment)
+			opts.attachment = (*C.z_moved_bytes_t)(unsafe.Pointer(ownedAttachment))
+		}
+		if options.Timestamp != nil {
+			ts, err := newTimestamp(options.Timestamp)
+			if err != nil {
+				return err
+			}
+			defer C.free(unsafe.Pointer(ts))
+			opts.timestamp = ts
 		}
 	}
 
-	var opts C.z_query_reply_options_t
-	C.z_query_reply_options_default(&opts)
+	var ownedBytes C.z_owned_bytes_t
+	if err := bytesFromGo(&ownedBytes, payload); err != nil {
+		return err
+	}
 
-	return Check(C.z_query_reply(q.ptr, loanedKeyExpr, (*C.z_moved_bytes_t)(unsafe.Pointer(&ownedBytes)), &opts))
+	return Check(C.z_query_reply(q.ptr, C.z_keyexpr_loan(&ownedKeyExpr), (*C.z_moved_bytes_t)(unsafe.Pointer(&ownedBytes)), &opts))
 }
 
-func (q *Query) ReplyErr(errMsg string) error {
-	var ownedBytes C.z_owned_bytes_t
-	if len(errMsg) > 0 {
-		cMsg := C.CBytes([]byte(errMsg))
-		defer C.free(cMsg)
-		ret := C.z_bytes_copy_from_buf(&ownedBytes, (*C.uint8_t)(cMsg), C.size_t(len(errMsg)))
-		if ret != 0 {
-			return Check(ret)
+// ReplyDelOptions mirrors z_query_reply_del_options_t. A zero Priority keeps the zenoh-c default.
+type ReplyDelOptions struct {
+	CongestionControl int
+	Priority          int
+	IsExpress         bool
+	Attachment        []byte
+	Timestamp         *TimestampData
+}
+
+// ReplyDel sends a DELETE reply to the query.
+// This is equivalent to z_query_reply_del() in zenoh-c.
+func (q *Query) ReplyDel(keyExpr string, options *ReplyDelOptions) error {
+	if q.ptr == nil {
+		return errQueryReleased
+	}
+	var ownedKeyExpr C.z_owned_keyexpr_t
+	if err := keyexprFromStr(&ownedKeyExpr, keyExpr); err != nil {
+		return err
+	}
+	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
+
+	var opts C.z_query_reply_del_options_t
+	C.z_query_reply_del_options_default(&opts)
+	if options != nil {
+		opts.congestion_control = C.enum_z_congestion_control_t(options.CongestionControl)
+		if options.Priority != 0 {
+			opts.priority = C.enum_z_priority_t(options.Priority)
+		}
+		opts.is_express = C.bool(options.IsExpress)
+		if options.Attachment != nil {
+			ownedAttachment, err := newOwnedBytes(options.Attachment)
+			if err != nil {
+				return err
+			}
+			defer freeOwnedBytes(ownedAttachment)
+			opts.attachment = (*C.z_moved_bytes_t)(unsafe.Pointer(ownedAttachment))
 		}
+		if options.Timestamp != nil {
+			ts, err := newTimestamp(options.Timestamp)
+			if err != nil {
+				return err
+			}
+			defer C.free(unsafe.Pointer(ts))
+			opts.timestamp = ts
+		}
+	}
+
+	return Check(C.z_query_reply_del(q.ptr, C.z_keyexpr_loan(&ownedKeyExpr), &opts))
+}
+
+func (q *Query) ReplyErr(errMsg string) error {
+	return q.ReplyErrWithEncoding([]byte(errMsg), nil)
+}
+
+// ReplyErrWithEncoding sends an error reply whose payload has the given encoding.
+// This is equivalent to z_query_reply_err() in zenoh-c.
+func (q *Query) ReplyErrWithEncoding(payload []byte, encoding *Encoding) error {
+	if q.ptr == nil {
+		return errQueryReleased
 	}
 
 	var opts C.z_query_reply_err_options_t
 	C.z_query_reply_err_options_default(&opts)
+	if encoding != nil {
+		ownedEnc, err := encoding.newOwned()
+		if err != nil {
+			return err
+		}
+		defer freeOwnedEncoding(ownedEnc)
+		opts.encoding = (*C.z_moved_encoding_t)(unsafe.Pointer(ownedEnc))
+	}
+
+	var ownedBytes C.z_owned_bytes_t
+	if err := bytesFromGo(&ownedBytes, payload); err != nil {
+		return err
+	}
 
 	return Check(C.z_query_reply_err(q.ptr, (*C.z_moved_bytes_t)(unsafe.Pointer(&ownedBytes)), &opts))
 }
 
 type QueryableCallback func(Query)
 
+// QueryableDoneCallback is called once the queryable has been undeclared.
+type QueryableDoneCallback func()
+
+type queryableHandler struct {
+	callback QueryableCallback
+	done     QueryableDoneCallback
+}
+
 //export goQueryCallback
 func goQueryCallback(query unsafe.Pointer, context unsafe.Pointer) {
 	handle := uintptr(context)
@@ -830,63 +1946,93 @@ func goQueryCallback(query unsafe.Pointer, context unsafe.Pointer) {
 	if !ok {
 		return
 	}
-	callback, ok := cb.(QueryableCallback)
+	handler, ok := cb.(*queryableHandler)
 	if !ok {
 		return
 	}
 
-	var keyExprBuf [256]byte
-	C.queryKeyexprToString((*C.z_loaned_query_t)(query), (*C.char)(unsafe.Pointer(&keyExprBuf)), C.size_t(256))
-	keyExpr := C.GoStringN((*C.char)(unsafe.Pointer(&keyExprBuf)), C.int(C.strlen((*C.char)(unsafe.Pointer(&keyExprBuf)))))
+	loaned := (*C.z_loaned_query_t)(query)
 
-	var paramsBuf [1024]byte
-	C.queryParametersToSlice((*C.z_loaned_query_t)(query), (*C.char)(unsafe.Pointer(&paramsBuf)), C.size_t(1024))
-	params := C.GoStringN((*C.char)(unsafe.Pointer(&paramsBuf)), C.int(C.strlen((*C.char)(unsafe.Pointer(&paramsBuf)))))
+	var params C.z_view_string_t
+	C.z_query_parameters(loaned, &params)
 
-	var payloadBuf [4096]byte
-	payloadLen := C.queryPayloadToSlice((*C.z_loaned_query_t)(query), (*C.uint8_t)(unsafe.Pointer(&payloadBuf)), C.size_t(4096))
-	payload := C.GoBytes(unsafe.Pointer(&payloadBuf), C.int(payloadLen))
+	q := Query{
+		ptr:        loaned,
+		KeyExpr:    keyexprToGo(C.z_query_keyexpr(loaned)),
+		Parameters: viewStringToGo(&params),
+		Payload:    bytesToGo(C.z_query_payload(loaned)),
+	}
+	if encoding := C.z_query_encoding(loaned); encoding != nil {
+		q.Encoding = encodingToString(encoding)
+	}
+	if attachment := C.z_query_attachment(loaned); attachment != nil {
+		q.Attachment = bytesToGo(attachment)
+	}
+	handler.callback(q)
+}
 
-	callback(Query{
-		ptr:        (*C.z_loaned_query_t)(query),
-		KeyExpr:    keyExpr,
-		Parameters: params,
-		Payload:    payload,
-	})
+// goQueryDropCallback runs when zenoh-c drops the query closure, which happens
+// when the queryable is undeclared or its session is closed.
+//
+//export goQueryDropCallback
+func goQueryDropCallback(context unsafe.Pointer) {
+	handle := uintptr(context)
+	cb, ok := queryableRegistry.Get(handle)
+	if !ok {
+		return
+	}
+	queryableRegistry.Unregister(handle)
+	if handler, ok := cb.(*queryableHandler); ok && handler.done != nil {
+		handler.done()
+	}
 }
 
 var queryableRegistry = NewCallbackRegistry()
 
+// QueryableOptions mirrors z_queryable_options_t. AllowedOrigin holds a z_locality_t value.
+type QueryableOptions struct {
+	Complete      bool
+	AllowedOrigin int
+}
+
 func (s *Session) DeclareQueryable(keyExpr string, callback QueryableCallback) (*Queryable, error) {
+	return s.DeclareQueryableWithOptions(KeyExprArg{Expr: keyExpr}, callback, nil, nil)
+}
+
+// DeclareQueryableWithOptions declares a queryable. The done callback, if any,
+// is called exactly once when the queryable is undeclared, including when the
+// declaration fails.
+// This is equivalent to z_declare_queryable() in zenoh-c.
+func (s *Session) DeclareQueryableWithOptions(keyExpr KeyExprArg, callback QueryableCallback, done QueryableDoneCallback, options *QueryableOptions) (*Queryable, error) {
 	if callback == nil {
 		return nil, errors.New("callback cannot be nil")
 	}
 
-	handle := queryableRegistry.Register(callback)
-
-	cKeyExpr := C.CString(keyExpr)
-	defer C.free(unsafe.Pointer(cKeyExpr))
-
 	var ownedKeyExpr C.z_owned_keyexpr_t
-	err := C.z_keyexpr_from_str(&ownedKeyExpr, cKeyExpr)
-	if err != 0 {
-		queryableRegistry.Unregister(handle)
-		return nil, Check(err)
+	if err := keyExpr.toOwned(&ownedKeyExpr); err != nil {
+		if done != nil {
+			done()
+		}
+		return nil, err
 	}
 	defer C.z_keyexpr_drop((*C.z_moved_keyexpr_t)(unsafe.Pointer(&ownedKeyExpr)))
 
-	loanedKeyExpr := C.z_keyexpr_loan(&ownedKeyExpr)
+	var opts C.z_queryable_options_t
+	C.z_queryable_options_default(&opts)
+	if options != nil {
+		opts.complete = C.bool(options.Complete)
+		opts.allowed_origin = C.enum_z_locality_t(options.AllowedOrigin)
+	}
+
+	handle := queryableRegistry.Register(&queryableHandler{callback: callback, done: done})
 
 	var closure C.z_owned_closure_query_t
 	C.createClosureQuery(&closure, unsafe.Pointer(handle))
 
-	var opts C.z_queryable_options_t
-	C.z_queryable_options_default(&opts)
-
 	var ownedQueryable C.z_owned_queryable_t
-	ret := C.z_declare_queryable(s.ptr, &ownedQueryable, loanedKeyExpr, (*C.z_moved_closure_query_t)(unsafe.Pointer(&closure)), &opts)
+	// On failure zenoh-c drops the closure, which unregisters the handle.
+	ret := C.z_declare_queryable(s.ptr, &ownedQueryable, C.z_keyexpr_loan(&ownedKeyExpr), (*C.z_moved_closure_query_t)(unsafe.Pointer(&closure)), &opts)
 	if ret != 0 {
-		queryableRegistry.Unregister(handle)
 		return nil, Check(ret)
 	}
 
@@ -909,18 +2055,130 @@ func QueryableFromPtr(ptr uintptr) *Queryable {
 	}
 }
 
+func QueryableFromOwnedPtr(ptr uintptr, owned unsafe.Pointer, handle uintptr) *Queryable {
+	return &Queryable{
+		ptr:    (*C.z_loaned_queryable_t)(unsafe.Pointer(ptr)),
+		owned:  (*C.z_owned_queryable_t)(owned),
+		Ptr:    ptr,
+		handle: handle,
+	}
+}
+
 func (q *Queryable) Handle() uintptr {
 	return q.handle
 }
 
+func (q *Queryable) OwnedPtr() unsafe.Pointer {
+	return unsafe.Pointer(q.owned)
+}
+
+// Undeclare undeclares the queryable.
+// This is equivalent to z_undeclare_queryable() in zenoh-c.
 func (q *Queryable) Undeclare() error {
-	if q.owned != nil {
-		C.z_queryable_drop((*C.z_moved_queryable_t)(unsafe.Pointer(q.owned)))
-		queryableRegistry.Unregister(q.handle)
-		q.owned = nil
-		q.ptr = nil
+	if q.owned == nil {
+		return nil
 	}
-	return nil
+	// Undeclaring drops the closure, which unregisters the handle.
+	ret := C.z_undeclare_queryable((*C.z_moved_queryable_t)(unsafe.Pointer(q.owned)))
+	q.owned = nil
+	q.ptr = nil
+	return Check(ret)
+}
+
+// =============================================================================
+// Scouting
+// =============================================================================
+
+// ZIDSize is the length in bytes of a zenoh ID.
+const ZIDSize = 16
+
+// zidToGo copies a zenoh ID into a Go array, keeping its little-endian layout.
+func zidToGo(zid *C.z_id_t) [ZIDSize]byte {
+	var out [ZIDSize]byte
+	copy(out[:], unsafe.Slice((*byte)(unsafe.Pointer(&zid.id[0])), ZIDSize))
+	return out
+}
+
+// HelloData carries the content of a z_loaned_hello_t. WhatAmI holds a z_whatami_t value.
+type HelloData struct {
+	ZID      [ZIDSize]byte
+	WhatAmI  int
+	Locators []string
+}
+
+type HelloCallback func(HelloData)
+
+var helloRegistry = NewCallbackRegistry()
+
+//export goHelloCallback
+func goHelloCallback(hello unsafe.Pointer, context unsafe.Pointer) {
+	handle := uintptr(context)
+	cb, ok := helloRegistry.Get(handle)
+	if !ok {
+		return
+	}
+	callback, ok := cb.(HelloCallback)
+	if !ok {
+		return
+	}
+
+	loaned := (*C.z_loaned_hello_t)(hello)
+	zid := C.z_hello_zid(loaned)
+
+	var locators C.z_owned_string_array_t
+	C.z_hello_locators(loaned, &locators)
+	loanedLocators := C.z_string_array_loan(&locators)
+	n := int(C.z_string_array_len(loanedLocators))
+	locatorList := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		str := C.z_string_array_get(loanedLocators, C.size_t(i))
+		locatorList = append(locatorList, C.GoStringN(C.z_string_data(str), C.int(C.z_string_len(str))))
+	}
+	C.z_string_array_drop((*C.z_moved_string_array_t)(unsafe.Pointer(&locators)))
+
+	data := HelloData{
+		WhatAmI:  int(C.z_hello_whatami(loaned)),
+		Locators: locatorList,
+	}
+	data.ZID = zidToGo(&zid)
+	callback(data)
+}
+
+// Scout runs z_scout and blocks until scouting completes. The configuration is
+// consumed; if cfg is nil the default configuration is used. what holds a
+// z_what_t bitmask and a zero timeoutMs keeps the zenoh-c default.
+func Scout(cfg *Config, what int, timeoutMs uint64, callback HelloCallback) error {
+	if callback == nil {
+		return errors.New("callback cannot be nil")
+	}
+
+	if cfg == nil || cfg.owned == nil {
+		var err error
+		cfg, err = ConfigDefault()
+		if err != nil {
+			return err
+		}
+	}
+
+	var opts C.z_scout_options_t
+	C.z_scout_options_default(&opts)
+	if what != 0 {
+		opts.what = C.enum_z_what_t(what)
+	}
+	if timeoutMs > 0 {
+		opts.timeout_ms = C.uint64_t(timeoutMs)
+	}
+
+	handle := helloRegistry.Register(callback)
+	defer helloRegistry.Unregister(handle)
+
+	var closure C.z_owned_closure_hello_t
+	C.createClosureHello(&closure, unsafe.Pointer(handle))
+
+	ret := C.z_scout((*C.z_moved_config_t)(unsafe.Pointer(cfg.owned)), (*C.z_moved_closure_hello_t)(unsafe.Pointer(&closure)), &opts)
+	cfg.owned = nil
+	cfg.ptr = nil
+	return Check(ret)
 }
 
 // =============================================================================
@@ -0,0 +1,137 @@
+package zenoh
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// ErrInvalidAttachment is returned when an attachment cannot be decoded.
+var ErrInvalidAttachment = errors.New("invalid attachment")
+
+// Attachment is user metadata carried alongside the payload of a put, a
+// delete, a query or a reply, such as trace IDs or schema versions.
+//
+// An attachment holds raw bytes. NewAttachmentFromMap and Map encode and
+// decode a map of strings in the format of zenoh's serializer, so the
+// attachment can be read by zenoh applications written in other languages.
+//
+// A nil *Attachment means that no attachment is present.
+type Attachment struct {
+	data []byte
+}
+
+// NewAttachment creates an attachment holding a copy of data.
+func NewAttachment(data []byte) *Attachment {
+	return &Attachment{data: append([]byte{}, data...)}
+}
+
+// NewAttachmentFromMap creates an attachment holding m, serialized as a
+// zenoh map of strings. Entries are written in key order.
+func NewAttachmentFromMap(m map[string]string) *Attachment {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	data := binary.AppendUvarint(nil, uint64(len(m)))
+	for _, k := range keys {
+		data = appendSerializedString(data, k)
+		data = appendSerializedString(data, m[k])
+	}
+	return &Attachment{data: data}
+}
+
+// Bytes returns the raw content of the attachment.
+func (a *Attachment) Bytes() []byte {
+	if a == nil {
+		return nil
+	}
+	return a.data
+}
+
+// Len returns the length of the attachment in bytes.
+func (a *Attachment) Len() int {
+	if a == nil {
+		return 0
+	}
+	return len(a.data)
+}
+
+// Map decodes an attachment created by NewAttachmentFromMap, or by zenoh's
+// serializer from a map of strings.
+// Returns ErrInvalidAttachment if the attachment holds anything else.
+func (a *Attachment) Map() (map[string]string, error) {
+	if a == nil {
+		return nil, ErrInvalidAttachment
+	}
+	data := a.data
+	count, n := binary.Uvarint(data)
+	// Every entry takes at least two bytes, which bounds count on malformed input.
+	if n <= 0 || count > uint64(len(data)-n)/2 {
+		return nil, ErrInvalidAttachment
+	}
+	data = data[n:]
+
+	m := make(map[string]string, count)
+	for i := uint64(0); i < count; i++ {
+		var k, v string
+		var ok bool
+		if k, data, ok = readSerializedString(data); !ok {
+			return nil, ErrInvalidAttachment
+		}
+		if v, data, ok = readSerializedString(data); !ok {
+			return nil, ErrInvalidAttachment
+		}
+		m[k] = v
+	}
+	if len(data) != 0 {
+		return nil, ErrInvalidAttachment
+	}
+	return m, nil
+}
+
+func (a *Attachment) String() string {
+	if a == nil {
+		return "<nil>"
+	}
+	return string(a.data)
+}
+
+// toCGO returns the bytes to send, or nil when there is no attachment.
+func (a *Attachment) toCGO() []byte {
+	if a == nil {
+		return nil
+	}
+	if a.data == nil {
+		return []byte{}
+	}
+	return a.data
+}
+
+// attachmentFromCGO wraps received attachment bytes. nil means no attachment.
+func attachmentFromCGO(data []byte) *Attachment {
+	if data == nil {
+		return nil
+	}
+	return &Attachment{data: data}
+}
+
+// appendSerializedString appends s in zenoh's serialization format:
+// its length as an unsigned LEB128 varint followed by its bytes.
+func appendSerializedString(data []byte, s string) []byte {
+	data = binary.AppendUvarint(data, uint64(len(s)))
+	return append(data, s...)
+}
+
+// readSerializedString reads a string written by appendSerializedString
+// and returns the remaining data.
+func readSerializedString(data []byte) (string, []byte, bool) {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)-n) {
+		return "", nil, false
+	}
+	data = data[n:]
+	return string(data[:size]), data[size:], true
+}
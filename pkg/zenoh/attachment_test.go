@@ -0,0 +1,118 @@
+package zenoh
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wind-c/zenoh-go/internal/cgo"
+)
+
+func TestAttachment_Bytes(t *testing.T) {
+	data := []byte("trace-id")
+	a := NewAttachment(data)
+	data[0] = 'X'
+
+	if got := string(a.Bytes()); got != "trace-id" {
+		t.Errorf("Bytes() = %q, want %q", got, "trace-id")
+	}
+	if a.Len() != len("trace-id") {
+		t.Errorf("Len() = %d, want %d", a.Len(), len("trace-id"))
+	}
+}
+
+func TestAttachment_Nil(t *testing.T) {
+	var a *Attachment
+	if a.Bytes() != nil {
+		t.Error("Bytes() on nil attachment should return nil")
+	}
+	if a.Len() != 0 {
+		t.Error("Len() on nil attachment should return 0")
+	}
+	if _, err := a.Map(); err != ErrInvalidAttachment {
+		t.Errorf("Map() on nil attachment error = %v, want %v", err, ErrInvalidAttachment)
+	}
+	if a.toCGO() != nil {
+		t.Error("toCGO() on nil attachment should return nil")
+	}
+	if attachmentFromCGO(nil) != nil {
+		t.Error("attachmentFromCGO(nil) should return nil")
+	}
+}
+
+func TestAttachment_EmptyToCGO(t *testing.T) {
+	if got := NewAttachment(nil).toCGO(); got == nil || len(got) != 0 {
+		t.Errorf("toCGO() on empty attachment = %v, want empty non-nil slice", got)
+	}
+}
+
+func TestAttachment_MapEncoding(t *testing.T) {
+	tests := []struct {
+		name string
+		m    map[string]string
+		want []byte
+	}{
+		{"empty", map[string]string{}, []byte{0x00}},
+		{"single", map[string]string{"a": "b"}, []byte{0x01, 0x01, 'a', 0x01, 'b'}},
+		{"sorted keys", map[string]string{"b": "", "a": "1"}, []byte{0x02, 0x01, 'a', 0x01, '1', 0x01, 'b', 0x00}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewAttachmentFromMap(tt.m).Bytes(); !bytes.Equal(got, tt.want) {
+				t.Errorf("NewAttachmentFromMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachment_MapRoundTrip(t *testing.T) {
+	m := map[string]string{
+		"trace_id":       "4bf92f3577b34da6",
+		"schema_version": "3",
+		"empty":          "",
+		"long":           strings.Repeat("x", 300),
+	}
+	got, err := NewAttachmentFromMap(m).Map()
+	if err != nil {
+		t.Fatalf("Map() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("Map() = %v, want %v", got, m)
+	}
+}
+
+func TestAttachment_MapInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"count too large", []byte{0x05, 0x01, 'a', 0x01, 'b'}},
+		{"truncated value", []byte{0x01, 0x01, 'a', 0x03, 'b'}},
+		{"missing value", []byte{0x01, 0x01, 'a'}},
+		{"trailing bytes", []byte{0x01, 0x01, 'a', 0x01, 'b', 0xff}},
+		{"raw text", []byte("not a map")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAttachment(tt.data).Map(); err != ErrInvalidAttachment {
+				t.Errorf("Map() error = %v, want %v", err, ErrInvalidAttachment)
+			}
+		})
+	}
+}
+
+func TestReplyFromCGO_Attachment(t *testing.T) {
+	reply := replyFromCGO(cgo.QueryReplyData{Ok: true, Attachment: []byte("meta")})
+	if got := string(reply.Attachment().Bytes()); got != "meta" {
+		t.Errorf("Reply.Attachment() = %q, want %q", got, "meta")
+	}
+
+	reply = replyFromCGO(cgo.QueryReplyData{Ok: true})
+	if reply.Attachment() != nil {
+		t.Error("Reply.Attachment() should be nil when the reply carries none")
+	}
+}
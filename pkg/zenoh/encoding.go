@@ -266,14 +266,20 @@ func (e *Encoding) IsBinary() bool {
 // =============================================================================
 
 // toCGO converts the encoding to CGO representation.
-// Returns nil as cgo encoding support is not yet implemented.
+// Returns nil for a nil or invalid encoding, in which case zenoh applies its default.
 func (e *Encoding) toCGO() *cgo.Encoding {
-	return nil
+	if !e.IsValid() {
+		return nil
+	}
+	return &cgo.Encoding{Value: e.String()}
 }
 
 // fromCGO converts CGO representation to Encoding.
 func fromCGO(enc *cgo.Encoding) *Encoding {
-	return nil
+	if enc == nil {
+		return nil
+	}
+	return EncodingFromStr(enc.Value)
 }
 
 // Drop releases the encoding resources.
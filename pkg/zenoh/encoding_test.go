@@ -407,3 +407,41 @@ func TestEncodingRoundTrip(t *testing.T) {
 		t.Errorf("Round-trip failed: got %v, want %v", decoded.String(), original.String())
 	}
 }
+
+func TestEncodingCGORoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		enc  *Encoding
+	}{
+		{"prefix only", EncodingApplicationJson},
+		{"with suffix", NewEncoding("text/plain").WithSuffix("utf8")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.enc.toCGO()
+			if c == nil {
+				t.Fatal("toCGO() returned nil for valid encoding")
+			}
+			if c.Value != tt.enc.String() {
+				t.Errorf("toCGO().Value = %v, want %v", c.Value, tt.enc.String())
+			}
+			if got := fromCGO(c); !got.Equals(tt.enc) {
+				t.Errorf("fromCGO() = %v, want %v", got, tt.enc)
+			}
+		})
+	}
+}
+
+func TestEncodingCGONil(t *testing.T) {
+	var e *Encoding
+	if e.toCGO() != nil {
+		t.Error("nil Encoding.toCGO() should return nil")
+	}
+	if (&Encoding{}).toCGO() != nil {
+		t.Error("invalid Encoding.toCGO() should return nil")
+	}
+	if fromCGO(nil) != nil {
+		t.Error("fromCGO(nil) should return nil")
+	}
+}
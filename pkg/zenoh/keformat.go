@@ -0,0 +1,251 @@
+package zenoh
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidKeFormat  = errors.New("invalid key expression format")
+	ErrKeFormatMismatch = errors.New("key expression does not match format")
+)
+
+// KeFormat is a compiled key expression format, such as
+// "robot/${id:*}/joint/${name:*}". It builds key expressions from named
+// values and parses key expressions back into them.
+//
+// A format is a key expression in which whole chunks are replaced by fields
+// written ${name:pattern} or ${name:pattern#default}. The pattern is a key
+// expression that the value of the field must be included in; it may span
+// several chunks, as in ${path:**}. A field with a default may be omitted
+// when building.
+//
+// Example:
+//
+//	format, err := zenoh.NewKeFormat("robot/${id:*}/joint/${name:*}")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	key, _ := format.Build(map[string]string{"id": "r1", "name": "elbow"})
+//	fields, _ := format.Parse(sample.KeyExpr) // {"id": "r1", "name": "elbow"}
+type KeFormat struct {
+	spec  string
+	parts []keFormatPart
+}
+
+// keFormatPart is a field, or a literal chunk of the format when name is empty.
+type keFormatPart struct {
+	name       string
+	pattern    []string
+	def        string
+	hasDefault bool
+}
+
+// NewKeFormat compiles a key expression format.
+func NewKeFormat(spec string) (*KeFormat, error) {
+	if spec == "" || validateKeyExpr(spec) != nil {
+		return nil, ErrInvalidKeFormat
+	}
+	f := &KeFormat{spec: spec}
+	names := make(map[string]bool)
+	for rest := spec; rest != ""; {
+		if !strings.HasPrefix(rest, "${") {
+			chunk, tail, _ := strings.Cut(rest, "/")
+			if chunk == "" || strings.Contains(chunk, "${") {
+				return nil, ErrInvalidKeFormat
+			}
+			f.parts = append(f.parts, keFormatPart{pattern: []string{chunk}})
+			rest = tail
+			continue
+		}
+
+		end := strings.IndexByte(rest, '}')
+		if end == -1 {
+			return nil, ErrInvalidKeFormat
+		}
+		part, ok := parseKeFormatField(rest[2:end])
+		if !ok || names[part.name] {
+			return nil, ErrInvalidKeFormat
+		}
+		names[part.name] = true
+		f.parts = append(f.parts, part)
+
+		rest = rest[end+1:]
+		if rest != "" {
+			var ok bool
+			if rest, ok = strings.CutPrefix(rest, "/"); !ok || rest == "" {
+				return nil, ErrInvalidKeFormat
+			}
+		}
+	}
+	if strings.HasSuffix(spec, "/") {
+		return nil, ErrInvalidKeFormat
+	}
+	return f, nil
+}
+
+// parseKeFormatField parses the "name:pattern#default" inside ${...}.
+func parseKeFormatField(field string) (keFormatPart, bool) {
+	name, pattern, ok := strings.Cut(field, ":")
+	if !ok || name == "" || strings.ContainsAny(name, "/$#") {
+		return keFormatPart{}, false
+	}
+	part := keFormatPart{name: name}
+	pattern, part.def, part.hasDefault = strings.Cut(pattern, "#")
+	if !isKeFormatPattern(pattern) {
+		return keFormatPart{}, false
+	}
+	part.pattern = parseKeySegments(pattern)
+	if part.hasDefault && !part.matches(part.def) {
+		return keFormatPart{}, false
+	}
+	return part, true
+}
+
+// isKeFormatPattern reports whether expr is a valid key expression without
+// empty chunks, as required of field patterns and values.
+func isKeFormatPattern(expr string) bool {
+	if expr == "" || validateKeyExpr(expr) != nil {
+		return false
+	}
+	for _, chunk := range strings.Split(expr, "/") {
+		if chunk == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether value is a valid value of the field. The empty
+// value stands for no chunk at all, which only patterns such as ** accept.
+func (p *keFormatPart) matches(value string) bool {
+	if value == "" {
+		return keyIncludesPattern(p.pattern, nil)
+	}
+	return isKeFormatPattern(value) && keyIncludesPattern(p.pattern, parseKeySegments(value))
+}
+
+// String returns the format spec.
+func (f *KeFormat) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.spec
+}
+
+// Fields returns the names of the fields of the format, in order.
+func (f *KeFormat) Fields() []string {
+	if f == nil {
+		return nil
+	}
+	var names []string
+	for _, p := range f.parts {
+		if p.name != "" {
+			names = append(names, p.name)
+		}
+	}
+	return names
+}
+
+// KeyExpr returns the key expression matching every key of the format, in
+// which each field is replaced by its pattern. It is the key expression to
+// subscribe to in order to receive the keys of the format.
+func (f *KeFormat) KeyExpr() string {
+	if f == nil {
+		return ""
+	}
+	chunks := make([]string, 0, len(f.parts))
+	for _, p := range f.parts {
+		chunks = append(chunks, strings.Join(p.pattern, "/"))
+	}
+	return strings.Join(chunks, "/")
+}
+
+// Build returns the key expression of the format with each field set to its
+// value in values, or to its default when it has none. A field whose pattern
+// accepts zero chunks, such as ${path:**}, may be set to "" to leave it out. It
+// fails with ErrInvalidValue if a field without default is missing, and with
+// ErrKeFormatMismatch if a value is not included in the pattern of its field or
+// if every field is empty and the format has no literal chunk.
+func (f *KeFormat) Build(values map[string]string) (string, error) {
+	if f == nil {
+		return "", ErrInvalidKeFormat
+	}
+	chunks := make([]string, 0, len(f.parts))
+	for _, p := range f.parts {
+		if p.name == "" {
+			chunks = append(chunks, p.pattern[0])
+			continue
+		}
+		value, ok := values[p.name]
+		if !ok {
+			if !p.hasDefault {
+				return "", ErrInvalidValue
+			}
+			value = p.def
+		}
+		if !p.matches(value) {
+			return "", ErrKeFormatMismatch
+		}
+		if value != "" {
+			chunks = append(chunks, value)
+		}
+	}
+	if len(chunks) == 0 {
+		return "", ErrKeFormatMismatch
+	}
+	return strings.Join(chunks, "/"), nil
+}
+
+// Parse returns the values of the fields of the format in keyExpr, such as
+// the key expression of a received Sample. When a field pattern spans a
+// variable number of chunks and several splits are possible, earlier fields
+// take as few chunks as possible. A field whose pattern matches no chunk,
+// such as ${path:**} matched against nothing, has an empty value.
+func (f *KeFormat) Parse(keyExpr string) (map[string]string, error) {
+	if f == nil {
+		return nil, ErrInvalidKeFormat
+	}
+	if !isKeFormatPattern(keyExpr) {
+		return nil, ErrInvalidKeyExpr
+	}
+	values := make(map[string]string)
+	if !f.parse(0, parseKeySegments(keyExpr), values, make(map[[2]int]struct{})) {
+		return nil, ErrKeFormatMismatch
+	}
+	return values, nil
+}
+
+// parse matches the parts of the format from the i-th one against chunks,
+// which are always the last chunks of the key. failed holds the (i,
+// len(chunks)) pairs already known not to match, so that formats with several
+// multi-chunk fields do not retry the same split over and over.
+func (f *KeFormat) parse(i int, chunks []string, values map[string]string, failed map[[2]int]struct{}) bool {
+	if i == len(f.parts) {
+		return len(chunks) == 0
+	}
+	state := [2]int{i, len(chunks)}
+	if _, ok := failed[state]; ok {
+		return false
+	}
+	p := &f.parts[i]
+	dwild := len(p.pattern) == 1 && p.pattern[0] == "**"
+	for n := 0; n <= len(chunks); n++ {
+		if dwild {
+			// ** includes every chunk up to the first verbatim one.
+			if n > 0 && isVerbatimChunk(chunks[n-1]) {
+				break
+			}
+		} else if !keyIncludesPattern(p.pattern, chunks[:n]) {
+			continue
+		}
+		if f.parse(i+1, chunks[n:], values, failed) {
+			if p.name != "" {
+				values[p.name] = strings.Join(chunks[:n], "/")
+			}
+			return true
+		}
+	}
+	failed[state] = struct{}{}
+	return false
+}
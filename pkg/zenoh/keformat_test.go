@@ -0,0 +1,201 @@
+package zenoh
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewKeFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{"fields", "robot/${id:*}/joint/${name:*}", false},
+		{"multi-chunk pattern", "logs/${path:**}", false},
+		{"default", "robot/${id:*#r0}/status", false},
+		{"empty default", "logs/${path:**#}", false},
+		{"empty default outside pattern", "robot/${id:*#}", true},
+		{"only a field", "${id:*}", false},
+		{"empty", "", true},
+		{"invalid key expression", "robot/***", true},
+		{"unterminated field", "robot/${id:*", true},
+		{"missing pattern", "robot/${id}", true},
+		{"empty name", "robot/${:*}", true},
+		{"duplicate field", "${id:*}/${id:*}", true},
+		{"field inside a chunk", "robot/r${id:*}", true},
+		{"field followed by a chunk", "robot/${id:*}x", true},
+		{"default outside pattern", "robot/${id:a*#b}", true},
+		{"empty chunk", "robot//${id:*}", true},
+		{"trailing slash", "robot/${id:*}/", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeFormat(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewKeFormat(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidKeFormat) {
+				t.Errorf("NewKeFormat(%q) error = %v, want ErrInvalidKeFormat", tt.spec, err)
+			}
+		})
+	}
+}
+
+func TestKeFormat_Accessors(t *testing.T) {
+	format, err := NewKeFormat("robot/${id:*}/logs/${path:**}")
+	if err != nil {
+		t.Fatalf("NewKeFormat() error = %v", err)
+	}
+	if got := format.String(); got != "robot/${id:*}/logs/${path:**}" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := format.Fields(); !slices.Equal(got, []string{"id", "path"}) {
+		t.Errorf("Fields() = %v", got)
+	}
+	if got := format.KeyExpr(); got != "robot/*/logs/**" {
+		t.Errorf("KeyExpr() = %q, want %q", got, "robot/*/logs/**")
+	}
+
+	var nilFormat *KeFormat
+	if nilFormat.String() != "" || nilFormat.Fields() != nil || nilFormat.KeyExpr() != "" {
+		t.Error("nil format should be empty")
+	}
+	if _, err := nilFormat.Build(nil); err != ErrInvalidKeFormat {
+		t.Errorf("Build() on nil error = %v, want ErrInvalidKeFormat", err)
+	}
+}
+
+func TestKeFormat_Build(t *testing.T) {
+	format, err := NewKeFormat("robot/${id:*}/joint/${name:*#base}/${path:**}")
+	if err != nil {
+		t.Fatalf("NewKeFormat() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    string
+		wantErr error
+	}{
+		{"all fields", map[string]string{"id": "r1", "name": "elbow", "path": "a/b"}, "robot/r1/joint/elbow/a/b", nil},
+		{"default", map[string]string{"id": "r1", "path": "a"}, "robot/r1/joint/base/a", nil},
+		{"wildcard value", map[string]string{"id": "*", "path": "**"}, "robot/*/joint/base/**", nil},
+		{"missing field", map[string]string{"path": "a"}, "", ErrInvalidValue},
+		{"value spans chunks", map[string]string{"id": "r1/r2", "path": "a"}, "", ErrKeFormatMismatch},
+		{"value wider than pattern", map[string]string{"id": "**", "path": "a"}, "", ErrKeFormatMismatch},
+		{"empty value", map[string]string{"id": "", "path": "a"}, "", ErrKeFormatMismatch},
+		{"empty multi-chunk value", map[string]string{"id": "r1", "path": ""}, "robot/r1/joint/base", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := format.Build(tt.values)
+			if err != tt.wantErr {
+				t.Fatalf("Build() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Build() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeFormat_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		keyExpr string
+		want    map[string]string
+		wantErr error
+	}{
+		{"fields", "robot/${id:*}/joint/${name:*}", "robot/r1/joint/elbow", map[string]string{"id": "r1", "name": "elbow"}, nil},
+		{"multi-chunk field", "logs/${path:**}/end", "logs/a/b/c/end", map[string]string{"path": "a/b/c"}, nil},
+		{"empty multi-chunk field", "logs/${path:**}", "logs", map[string]string{"path": ""}, nil},
+		{"earlier field shortest", "${a:**}/${b:**}", "x/y", map[string]string{"a": "", "b": "x/y"}, nil},
+		{"sub-chunk pattern", "robot/${id:r$*}", "robot/r42", map[string]string{"id": "r42"}, nil},
+		{"wildcard key", "robot/${id:*}/status", "robot/*/status", map[string]string{"id": "*"}, nil},
+		{"literal mismatch", "robot/${id:*}/status", "drone/r1/status", nil, ErrKeFormatMismatch},
+		{"pattern mismatch", "robot/${id:r$*}", "robot/x1", nil, ErrKeFormatMismatch},
+		{"too many chunks", "robot/${id:*}", "robot/r1/extra", nil, ErrKeFormatMismatch},
+		{"verbatim chunk", "logs/${path:**}/end", "logs/a/@v/end", nil, ErrKeFormatMismatch},
+		{"invalid key expression", "robot/${id:*}", "robot//r1", nil, ErrInvalidKeyExpr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := NewKeFormat(tt.spec)
+			if err != nil {
+				t.Fatalf("NewKeFormat() error = %v", err)
+			}
+			got, err := format.Parse(tt.keyExpr)
+			if err != tt.wantErr {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeFormat_Parse_LongMismatch(t *testing.T) {
+	// Without memoization, each ** field would retry every split of the
+	// chunks left by the previous ones.
+	format, err := NewKeFormat("${a:**}/x/${b:**}/x/${c:**}/y")
+	if err != nil {
+		t.Fatalf("NewKeFormat() error = %v", err)
+	}
+	key := strings.Repeat("x/", 200) + "z"
+	if _, err := format.Parse(key); err != ErrKeFormatMismatch {
+		t.Fatalf("Parse() error = %v, want %v", err, ErrKeFormatMismatch)
+	}
+}
+
+func TestKeFormat_RoundTrip(t *testing.T) {
+	tests := []struct {
+		spec   string
+		values map[string]string
+		key    string
+	}{
+		{"fleet/${site:*}/robot/${id:*}/${topic:**}", map[string]string{"site": "lyon", "id": "r7", "topic": "sensors/temp"}, "fleet/lyon/robot/r7/sensors/temp"},
+		{"a/${p:**}/b", map[string]string{"p": "x/y"}, "a/x/y/b"},
+		{"a/${p:**}/b", map[string]string{"p": ""}, "a/b"},
+		{"${p:**}/b", map[string]string{"p": ""}, "b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			format, err := NewKeFormat(tt.spec)
+			if err != nil {
+				t.Fatalf("NewKeFormat() error = %v", err)
+			}
+			key, err := format.Build(tt.values)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if key != tt.key {
+				t.Errorf("Build() = %q, want %q", key, tt.key)
+			}
+			got, err := format.Parse(key)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", key, err)
+			}
+			if !maps.Equal(got, tt.values) {
+				t.Errorf("Parse(Build()) = %v, want %v", got, tt.values)
+			}
+		})
+	}
+
+	format, err := NewKeFormat("${p:**}")
+	if err != nil {
+		t.Fatalf("NewKeFormat() error = %v", err)
+	}
+	if _, err := format.Build(map[string]string{"p": ""}); err != ErrKeFormatMismatch {
+		t.Errorf("Build() of an empty key error = %v, want ErrKeFormatMismatch", err)
+	}
+}
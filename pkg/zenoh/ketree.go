@@ -0,0 +1,311 @@
+package zenoh
+
+import "strings"
+
+// KeTree maps key expressions to values and finds the key expressions that
+// intersect or include a given one without comparing it against every entry.
+// Key expressions are stored chunk by chunk in a tree, so a lookup only walks
+// the branches whose chunks can match.
+//
+// A KeTree is not safe for concurrent use; guard it with a sync.RWMutex when
+// it is shared between goroutines.
+//
+// Example:
+//
+//	routes := zenoh.NewKeTree[func(zenoh.Sample)]()
+//	routes.Insert("fleet/*/sensors/**", handleSensors)
+//	for _, route := range routes.Intersecting(sample.KeyExpr) {
+//	    route.Value(sample)
+//	}
+type KeTree[V any] struct {
+	root keTreeNode[V]
+	size int
+}
+
+// KeTreeEntry is a key expression stored in a KeTree with its value.
+type KeTreeEntry[V any] struct {
+	KeyExpr string
+	Value   V
+}
+
+type keTreeNode[V any] struct {
+	children map[string]*keTreeNode[V]
+	// wildChildren lists the chunks of children that contain a wildcard,
+	// which are the only ones a literal chunk may match besides itself.
+	wildChildren []string
+	parent       *keTreeNode[V]
+	chunk        string
+	keyExpr      string
+	value        V
+	hasValue     bool
+}
+
+// NewKeTree returns an empty KeTree.
+func NewKeTree[V any]() *KeTree[V] {
+	return &KeTree[V]{}
+}
+
+// Len returns the number of key expressions in the tree.
+func (t *KeTree[V]) Len() int {
+	if t == nil {
+		return 0
+	}
+	return t.size
+}
+
+// Insert stores value under the canon form of keyExpr, replacing the value
+// of an equivalent key expression already in the tree.
+func (t *KeTree[V]) Insert(keyExpr string, value V) error {
+	if t == nil {
+		return ErrInvalidValue
+	}
+	canon, err := canonKeyExpr(keyExpr)
+	if err != nil {
+		return err
+	}
+	if canon == "" {
+		return ErrInvalidKeyExpr
+	}
+	node := &t.root
+	for _, chunk := range parseKeySegments(canon) {
+		child := node.children[chunk]
+		if child == nil {
+			child = &keTreeNode[V]{parent: node, chunk: chunk}
+			if node.children == nil {
+				node.children = make(map[string]*keTreeNode[V])
+			}
+			node.children[chunk] = child
+			if strings.Contains(chunk, "*") {
+				node.wildChildren = append(node.wildChildren, chunk)
+			}
+		}
+		node = child
+	}
+	if !node.hasValue {
+		t.size++
+	}
+	node.keyExpr = canon
+	node.value = value
+	node.hasValue = true
+	return nil
+}
+
+// Get returns the value stored under the canon form of keyExpr.
+func (t *KeTree[V]) Get(keyExpr string) (V, bool) {
+	var zero V
+	node := t.find(keyExpr)
+	if node == nil || !node.hasValue {
+		return zero, false
+	}
+	return node.value, true
+}
+
+// Remove removes the key expression equivalent to keyExpr from the tree and
+// reports whether it was present.
+func (t *KeTree[V]) Remove(keyExpr string) bool {
+	node := t.find(keyExpr)
+	if node == nil || !node.hasValue {
+		return false
+	}
+	var zero V
+	node.value = zero
+	node.keyExpr = ""
+	node.hasValue = false
+	t.size--
+
+	for node.parent != nil && !node.hasValue && len(node.children) == 0 {
+		parent := node.parent
+		delete(parent.children, node.chunk)
+		for i, chunk := range parent.wildChildren {
+			if chunk == node.chunk {
+				parent.wildChildren = append(parent.wildChildren[:i], parent.wildChildren[i+1:]...)
+				break
+			}
+		}
+		node = parent
+	}
+	return true
+}
+
+func (t *KeTree[V]) find(keyExpr string) *keTreeNode[V] {
+	if t == nil {
+		return nil
+	}
+	canon, err := canonKeyExpr(keyExpr)
+	if err != nil || canon == "" {
+		return nil
+	}
+	node := &t.root
+	for _, chunk := range parseKeySegments(canon) {
+		if node = node.children[chunk]; node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// Intersecting returns the entries whose key expression intersects keyExpr,
+// in no particular order. For a key without wildcards, these are the entries
+// matching it.
+//
+// keyExpr is not canonized, so that dispatching received samples does not go
+// through zenoh-c; key expressions received from zenoh are already in canon
+// form. An invalid keyExpr matches nothing.
+func (t *KeTree[V]) Intersecting(keyExpr string) []KeTreeEntry[V] {
+	w := t.newKeTreeWalk(keyExpr)
+	if w == nil {
+		return nil
+	}
+	w.intersectNode(&t.root, 0)
+	return w.entries
+}
+
+// Including returns the entries whose key expression includes keyExpr, in no
+// particular order. For a key without wildcards, this is the same as
+// Intersecting. As with Intersecting, keyExpr must be in canon form.
+func (t *KeTree[V]) Including(keyExpr string) []KeTreeEntry[V] {
+	w := t.newKeTreeWalk(keyExpr)
+	if w == nil {
+		return nil
+	}
+	w.includeNode(&t.root, 0)
+	return w.entries
+}
+
+// keTreeState is a node of the tree, or the edge to one of them when edge is
+// set, reached after matching the first chunks of the key expression looked up.
+type keTreeState[V any] struct {
+	node  *keTreeNode[V]
+	edge  bool
+	chunk int
+}
+
+// keTreeWalk runs the matching of keyIncludesPattern and keyIntersectsPattern
+// over every key expression of the tree at once.
+type keTreeWalk[V any] struct {
+	key     []string
+	seen    map[keTreeState[V]]struct{}
+	found   map[*keTreeNode[V]]struct{}
+	entries []KeTreeEntry[V]
+}
+
+func (t *KeTree[V]) newKeTreeWalk(keyExpr string) *keTreeWalk[V] {
+	if t == nil || t.size == 0 || keyExpr == "" || validateKeyExpr(keyExpr) != nil {
+		return nil
+	}
+	return &keTreeWalk[V]{
+		key:   parseKeySegments(keyExpr),
+		seen:  make(map[keTreeState[V]]struct{}),
+		found: make(map[*keTreeNode[V]]struct{}),
+	}
+}
+
+// visit reports whether the state is reached for the first time.
+func (w *keTreeWalk[V]) visit(node *keTreeNode[V], edge bool, chunk int) bool {
+	state := keTreeState[V]{node: node, edge: edge, chunk: chunk}
+	if _, ok := w.seen[state]; ok {
+		return false
+	}
+	w.seen[state] = struct{}{}
+	return true
+}
+
+func (w *keTreeWalk[V]) collect(node *keTreeNode[V]) {
+	if _, ok := w.found[node]; ok {
+		return
+	}
+	w.found[node] = struct{}{}
+	w.entries = append(w.entries, KeTreeEntry[V]{KeyExpr: node.keyExpr, Value: node.value})
+}
+
+// candidates calls fn for each child of node whose chunk may match the j-th
+// chunk of the key: the child with the same chunk and the wildcard children,
+// or every child when that chunk is itself a wildcard.
+func (w *keTreeWalk[V]) candidates(node *keTreeNode[V], j int, fn func(*keTreeNode[V])) {
+	if j < len(w.key) && strings.Contains(w.key[j], "*") {
+		for _, child := range node.children {
+			fn(child)
+		}
+		return
+	}
+	if j < len(w.key) {
+		if child := node.children[w.key[j]]; child != nil {
+			fn(child)
+		}
+	}
+	for _, chunk := range node.wildChildren {
+		fn(node.children[chunk])
+	}
+}
+
+func (w *keTreeWalk[V]) intersectNode(node *keTreeNode[V], j int) {
+	if !w.visit(node, false, j) {
+		return
+	}
+	if node.hasValue && onlyDWilds(w.key[j:]) {
+		w.collect(node)
+	}
+	w.candidates(node, j, func(child *keTreeNode[V]) {
+		w.intersectEdge(child, j)
+	})
+}
+
+// intersectEdge matches the chunk of node against the key from its j-th chunk.
+func (w *keTreeWalk[V]) intersectEdge(node *keTreeNode[V], j int) {
+	if !w.visit(node, true, j) {
+		return
+	}
+	switch {
+	case node.chunk == "**":
+		w.intersectNode(node, j)
+		if j < len(w.key) && !isVerbatimChunk(w.key[j]) {
+			w.intersectEdge(node, j+1)
+		}
+	case j < len(w.key) && w.key[j] == "**":
+		w.intersectEdge(node, j+1)
+		if !isVerbatimChunk(node.chunk) {
+			w.intersectNode(node, j)
+		}
+	case j < len(w.key) && chunkIntersects(node.chunk, w.key[j]):
+		w.intersectNode(node, j+1)
+	}
+}
+
+func (w *keTreeWalk[V]) includeNode(node *keTreeNode[V], j int) {
+	if !w.visit(node, false, j) {
+		return
+	}
+	if node.hasValue && j == len(w.key) {
+		w.collect(node)
+	}
+	w.candidates(node, j, func(child *keTreeNode[V]) {
+		w.includeEdge(child, j)
+	})
+}
+
+// includeEdge matches the chunk of node against the key from its j-th chunk.
+func (w *keTreeWalk[V]) includeEdge(node *keTreeNode[V], j int) {
+	if !w.visit(node, true, j) {
+		return
+	}
+	switch {
+	case node.chunk == "**":
+		w.includeNode(node, j)
+		if j < len(w.key) && !isVerbatimChunk(w.key[j]) {
+			w.includeEdge(node, j+1)
+		}
+	case j < len(w.key) && w.key[j] != "**" && chunkIncludes(node.chunk, w.key[j]):
+		w.includeNode(node, j+1)
+	}
+}
+
+// onlyDWilds reports whether every chunk is "**", so that the chunks can
+// match an empty key.
+func onlyDWilds(chunks []string) bool {
+	for _, chunk := range chunks {
+		if chunk != "**" {
+			return false
+		}
+	}
+	return true
+}
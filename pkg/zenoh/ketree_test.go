@@ -0,0 +1,234 @@
+package zenoh
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestKeTree_InsertGetRemove(t *testing.T) {
+	tree := NewKeTree[int]()
+
+	if err := tree.Insert("", 0); err != ErrInvalidKeyExpr {
+		t.Errorf("Insert(\"\") error = %v, want ErrInvalidKeyExpr", err)
+	}
+	if err := tree.Insert("demo/***", 0); err != ErrInvalidKeyExpr {
+		t.Errorf("Insert(\"demo/***\") error = %v, want ErrInvalidKeyExpr", err)
+	}
+
+	for i, expr := range []string{"demo/a", "demo/*", "demo/a/b"} {
+		if err := tree.Insert(expr, i); err != nil {
+			t.Fatalf("Insert(%q) error = %v", expr, err)
+		}
+	}
+	if err := tree.Insert("demo/a", 10); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if tree.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", tree.Len())
+	}
+	if v, ok := tree.Get("demo/a"); !ok || v != 10 {
+		t.Errorf("Get(\"demo/a\") = %d, %v, want 10, true", v, ok)
+	}
+	if _, ok := tree.Get("demo"); ok {
+		t.Error("Get(\"demo\") found a value for an inner node")
+	}
+
+	if !tree.Remove("demo/a/b") || tree.Remove("demo/a/b") {
+		t.Error("Remove() should report true once, then false")
+	}
+	if tree.Remove("demo") {
+		t.Error("Remove(\"demo\") removed an inner node")
+	}
+	tree.Remove("demo/a")
+	tree.Remove("demo/*")
+	if tree.Len() != 0 || len(tree.root.children) != 0 {
+		t.Errorf("tree not pruned: Len() = %d, root children = %d", tree.Len(), len(tree.root.children))
+	}
+
+	var nilTree *KeTree[int]
+	if nilTree.Len() != 0 || nilTree.Intersecting("demo") != nil || nilTree.Remove("demo") {
+		t.Error("nil tree should be empty")
+	}
+}
+
+func TestKeTree_Lookup(t *testing.T) {
+	tree := NewKeTree[string]()
+	for _, expr := range []string{
+		"fleet/*/sensors/**",
+		"fleet/r1/sensors/temp",
+		"fleet/r2/**",
+		"fleet/@admin/**",
+		"**/temp",
+		"fleet/r$*/status",
+	} {
+		if err := tree.Insert(expr, expr); err != nil {
+			t.Fatalf("Insert(%q) error = %v", expr, err)
+		}
+	}
+
+	tests := []struct {
+		key          string
+		intersecting []string
+		including    []string
+	}{
+		{
+			key:          "fleet/r1/sensors/temp",
+			intersecting: []string{"**/temp", "fleet/*/sensors/**", "fleet/r1/sensors/temp"},
+			including:    []string{"**/temp", "fleet/*/sensors/**", "fleet/r1/sensors/temp"},
+		},
+		{
+			key:          "fleet/r2/sensors",
+			intersecting: []string{"fleet/*/sensors/**", "fleet/r2/**"},
+			including:    []string{"fleet/*/sensors/**", "fleet/r2/**"},
+		},
+		{
+			key:          "fleet/r3/status",
+			intersecting: []string{"fleet/r$*/status"},
+			including:    []string{"fleet/r$*/status"},
+		},
+		{
+			key:          "fleet/@admin/sensors/temp",
+			intersecting: []string{"fleet/@admin/**"},
+			including:    []string{"fleet/@admin/**"},
+		},
+		{
+			key:          "fleet/*/sensors/**",
+			intersecting: []string{"**/temp", "fleet/*/sensors/**", "fleet/r1/sensors/temp", "fleet/r2/**"},
+			including:    []string{"fleet/*/sensors/**"},
+		},
+		{
+			key:          "other/key",
+			intersecting: nil,
+			including:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := keTreeKeys(tree.Intersecting(tt.key)); !slices.Equal(got, tt.intersecting) {
+				t.Errorf("Intersecting() = %v, want %v", got, tt.intersecting)
+			}
+			if got := keTreeKeys(tree.Including(tt.key)); !slices.Equal(got, tt.including) {
+				t.Errorf("Including() = %v, want %v", got, tt.including)
+			}
+		})
+	}
+}
+
+func TestKeTree_MatchesLinearScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tree := NewKeTree[int]()
+	var patterns []string
+	for len(patterns) < 200 {
+		expr := randomCanonKeyExpr(rng)
+		if _, ok := tree.Get(expr); ok {
+			continue
+		}
+		if err := tree.Insert(expr, len(patterns)); err != nil {
+			t.Fatalf("Insert(%q) error = %v", expr, err)
+		}
+		patterns = append(patterns, expr)
+	}
+
+	for range 500 {
+		key := randomCanonKeyExpr(rng)
+		var intersecting, including []string
+		for _, p := range patterns {
+			if keyIntersectsPattern(parseKeySegments(p), parseKeySegments(key)) {
+				intersecting = append(intersecting, p)
+			}
+			if keyIncludesPattern(parseKeySegments(p), parseKeySegments(key)) {
+				including = append(including, p)
+			}
+		}
+		slices.Sort(intersecting)
+		slices.Sort(including)
+
+		if got := keTreeKeys(tree.Intersecting(key)); !slices.Equal(got, intersecting) {
+			t.Fatalf("Intersecting(%q) = %v, want %v", key, got, intersecting)
+		}
+		if got := keTreeKeys(tree.Including(key)); !slices.Equal(got, including) {
+			t.Fatalf("Including(%q) = %v, want %v", key, got, including)
+		}
+	}
+}
+
+// keTreeKeys returns the sorted key expressions of entries.
+func keTreeKeys[V any](entries []KeTreeEntry[V]) []string {
+	var keys []string
+	for _, e := range entries {
+		keys = append(keys, e.KeyExpr)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// randomCanonKeyExpr returns a key expression of up to four chunks that is
+// already in canon form.
+func randomCanonKeyExpr(rng *rand.Rand) string {
+	alphabet := []string{"a", "b", "@a", "*", "a$*", "**"}
+	chunks := make([]string, 1+rng.Intn(4))
+	for i := range chunks {
+		chunks[i] = alphabet[rng.Intn(len(alphabet))]
+		// "**/**" and "**/*" are not canon.
+		if i > 0 && chunks[i-1] == "**" && (chunks[i] == "*" || chunks[i] == "**") {
+			chunks[i] = alphabet[rng.Intn(3)]
+		}
+	}
+	return strings.Join(chunks, "/")
+}
+
+// benchmarkRoutes returns n patterns shaped like the routes of a gateway.
+func benchmarkRoutes(n int) []string {
+	routes := make([]string, 0, n)
+	for i := 0; len(routes) < n; i++ {
+		routes = append(routes,
+			fmt.Sprintf("fleet/r%d/sensors/**", i),
+			fmt.Sprintf("fleet/*/sensors/s%d", i),
+			fmt.Sprintf("fleet/r%d/status", i),
+			fmt.Sprintf("site%d/**", i),
+		)
+	}
+	return routes[:n]
+}
+
+func BenchmarkKeTree_Intersecting(b *testing.B) {
+	for _, n := range []int{100, 10000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			tree := NewKeTree[int]()
+			for i, route := range benchmarkRoutes(n) {
+				if err := tree.Insert(route, i); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				tree.Intersecting("fleet/r42/sensors/s7")
+			}
+		})
+	}
+}
+
+func BenchmarkLinearScan_Intersecting(b *testing.B) {
+	for _, n := range []int{100, 10000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			var patterns [][]string
+			for _, route := range benchmarkRoutes(n) {
+				patterns = append(patterns, parseKeySegments(route))
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := parseKeySegments("fleet/r42/sensors/s7")
+				var matches []int
+				for j, p := range patterns {
+					if keyIntersectsPattern(p, key) {
+						matches = append(matches, j)
+					}
+				}
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"log"
 	"strings"
+
+	"github.com/wind-c/zenoh-go/internal/cgo"
 )
 
 var ErrInvalidKeyExpr = errors.New("invalid key expression")
@@ -74,36 +76,123 @@ func KeyExprFromStr(expr string) (*KeyExpr, error) {
 	return NewKeyExpr(expr)
 }
 
+// KeyExprRelation describes how the sets of keys matched by two key
+// expressions relate to each other.
+type KeyExprRelation int
+
+const (
+	// KeyExprRelationDisjoint means no key is matched by both key expressions.
+	KeyExprRelationDisjoint KeyExprRelation = 0
+	// KeyExprRelationIntersects means some keys are matched by both key expressions.
+	KeyExprRelationIntersects KeyExprRelation = 1
+	// KeyExprRelationIncludes means every key matched by the other key expression
+	// is also matched by this one.
+	KeyExprRelationIncludes KeyExprRelation = 2
+	// KeyExprRelationEquals means both key expressions match the same keys.
+	KeyExprRelationEquals KeyExprRelation = 3
+)
+
+// String returns the string representation of the KeyExprRelation.
+func (r KeyExprRelation) String() string {
+	switch r {
+	case KeyExprRelationDisjoint:
+		return "disjoint"
+	case KeyExprRelationIntersects:
+		return "intersects"
+	case KeyExprRelationIncludes:
+		return "includes"
+	case KeyExprRelationEquals:
+		return "equals"
+	default:
+		return "unknown"
+	}
+}
+
+// canonKeyExpr drops the empty chunks of expr, then canonizes it with zenoh-c.
+// An expression made only of slashes canonizes to the empty string.
+func canonKeyExpr(expr string) (string, error) {
+	impl, err := newKeyExprImpl(expr)
+	if err != nil {
+		return "", err
+	}
+	segments := make([]string, 0, len(impl.segments))
+	for _, seg := range impl.segments {
+		if seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	if len(segments) == 0 {
+		return "", nil
+	}
+	canon, err := cgo.KeyExprCanonize(strings.Join(segments, "/"))
+	if err != nil {
+		return "", ErrInvalidKeyExpr
+	}
+	return canon, nil
+}
+
+// Join joins two key expressions with a '/' and returns the result in canon
+// form. Leading and trailing slashes are ignored and an empty side yields the
+// other one.
+// This is equivalent to z_keyexpr_join() in zenoh-c.
 func Join(a, b string) (string, error) {
 	if a == "" && b == "" {
 		return "", ErrInvalidKeyExpr
 	}
-	a = strings.TrimSuffix(a, "/")
-	b = strings.TrimPrefix(b, "/")
+	var err error
+	if a != "" {
+		if a, err = canonKeyExpr(a); err != nil {
+			return "", err
+		}
+	}
+	if b != "" {
+		if b, err = canonKeyExpr(b); err != nil {
+			return "", err
+		}
+	}
 	if a == "" {
 		return b, nil
 	}
 	if b == "" {
 		return a, nil
 	}
-	return a + "/" + b, nil
+	joined, err := cgo.KeyExprJoin(a, b)
+	if err != nil {
+		return "", ErrInvalidKeyExpr
+	}
+	return joined, nil
 }
 
-func (k *KeyExpr) Canonize() (string, error) {
-	if k == nil {
+// Concat appends suffix to the key expression a without inserting a '/'.
+// It fails if the result is not a valid key expression, for example when
+// suffix starts with a wildcard that would merge with a trailing one of a.
+// Prefer Join when the suffix is a chunk of its own.
+// This is equivalent to z_keyexpr_concat() in zenoh-c.
+func Concat(a, suffix string) (string, error) {
+	canon, err := canonKeyExpr(a)
+	if err != nil {
+		return "", err
+	}
+	if canon == "" || validateKeyExpr(suffix) != nil {
 		return "", ErrInvalidKeyExpr
 	}
-	impl, err := newKeyExprImpl(k.expr)
+	concat, err := cgo.KeyExprConcat(canon, suffix)
 	if err != nil {
-		return "", err
+		return "", ErrInvalidKeyExpr
 	}
-	segments := make([]string, 0)
-	for _, seg := range impl.segments {
-		if seg != "" {
-			segments = append(segments, seg)
-		}
+	return concat, nil
+}
+
+// Canonize returns the canon form of the key expression, in which empty
+// chunks are dropped and wildcards are rewritten so that equivalent key
+// expressions have the same string (for example "a/**/**" becomes "a/**"
+// and "a/$*" becomes "a/*").
+// This is equivalent to z_keyexpr_canonize() in zenoh-c.
+func (k *KeyExpr) Canonize() (string, error) {
+	if k == nil {
+		return "", ErrInvalidKeyExpr
 	}
-	return strings.Join(segments, "/"), nil
+	return canonKeyExpr(k.expr)
 }
 
 func CanonizeString(expr string) (string, error) {
@@ -114,181 +203,232 @@ func CanonizeString(expr string) (string, error) {
 	return ke.Canonize()
 }
 
-func (k *KeyExpr) Includes(other *KeyExpr) (bool, error) {
+// canonPair canonizes the key expressions of k and other.
+func (k *KeyExpr) canonPair(other *KeyExpr) (string, string, error) {
 	if k == nil || other == nil {
-		return false, ErrInvalidKeyExpr
+		return "", "", ErrInvalidKeyExpr
 	}
-	if k.expr == other.expr {
-		return true, nil
+	a, err := canonKeyExpr(k.expr)
+	if err != nil {
+		return "", "", err
 	}
-	implA, _ := newKeyExprImpl(k.expr)
-	implB, _ := newKeyExprImpl(other.expr)
-	if !implA.hasWild {
-		return k.expr == other.expr, nil
+	b, err := canonKeyExpr(other.expr)
+	if err != nil {
+		return "", "", err
 	}
-	if !implB.hasWild {
-		return keyMatchesPattern(implA.expr, implA.segments, implB.expr), nil
+	if a == "" || b == "" {
+		return "", "", ErrInvalidKeyExpr
 	}
-	return keyIncludesPattern(implA.segments, implB.segments), nil
+	return a, b, nil
 }
 
-func (k *KeyExpr) Intersects(other *KeyExpr) (bool, error) {
-	if k == nil || other == nil {
-		return false, ErrInvalidKeyExpr
+// Includes reports whether every key matched by other is also matched by k.
+// This is equivalent to z_keyexpr_includes() in zenoh-c.
+func (k *KeyExpr) Includes(other *KeyExpr) (bool, error) {
+	a, b, err := k.canonPair(other)
+	if err != nil {
+		return false, err
 	}
-	if k.expr == other.expr {
+	if a == b {
 		return true, nil
 	}
-	implA, _ := newKeyExprImpl(k.expr)
-	implB, _ := newKeyExprImpl(other.expr)
-	if !implA.hasWild && !implB.hasWild {
-		return k.expr == other.expr, nil
+	includes, err := cgo.KeyExprIncludes(a, b)
+	if err != nil {
+		return false, ErrInvalidKeyExpr
 	}
-	return keyIntersectsPattern(implA.segments, implB.segments), nil
+	return includes, nil
 }
 
-func keyMatchesPattern(pattern string, patternSegs []string, key string) bool {
-	keySegs := parseKeySegments(key)
-	return matchKeySegments(patternSegs, keySegs)
+// Intersects reports whether at least one key is matched by both k and other.
+// This is equivalent to z_keyexpr_intersects() in zenoh-c.
+func (k *KeyExpr) Intersects(other *KeyExpr) (bool, error) {
+	a, b, err := k.canonPair(other)
+	if err != nil {
+		return false, err
+	}
+	if a == b {
+		return true, nil
+	}
+	intersects, err := cgo.KeyExprIntersects(a, b)
+	if err != nil {
+		return false, ErrInvalidKeyExpr
+	}
+	return intersects, nil
 }
 
-func matchKeySegments(pattern, key []string) bool {
-	if len(pattern) == 0 {
-		return len(key) == 0
+// RelationTo returns how the keys matched by k relate to those matched by other.
+// It is slower than Includes and Intersects, which should be preferred when
+// only one of the relations matters.
+// This is equivalent to z_keyexpr_relation_to() in zenoh-c.
+func (k *KeyExpr) RelationTo(other *KeyExpr) (KeyExprRelation, error) {
+	a, b, err := k.canonPair(other)
+	if err != nil {
+		return KeyExprRelationDisjoint, err
 	}
-	dWildIdx := -1
-	for i, seg := range pattern {
-		if seg == "**" {
-			dWildIdx = i
-			break
-		}
+	relation, err := cgo.KeyExprRelationTo(a, b)
+	if err != nil {
+		return KeyExprRelationDisjoint, ErrInvalidKeyExpr
 	}
-	if dWildIdx == -1 {
-		if len(pattern) != len(key) {
-			return false
+	return KeyExprRelation(relation), nil
+}
+
+// keyIncludesPattern is the pure-Go counterpart of z_keyexpr_includes on the
+// chunks of two canon key expressions: it reports whether every key matched
+// by b is also matched by a.
+func keyIncludesPattern(a, b []string) bool {
+	memo := make(map[[2]int]bool)
+	var includes func(i, j int) bool
+	includes = func(i, j int) bool {
+		if r, ok := memo[[2]int{i, j}]; ok {
+			return r
 		}
-		for i := range pattern {
-			if !matchWildSegment(pattern[i], key[i]) {
-				return false
-			}
+		var r bool
+		switch {
+		case i == len(a):
+			r = j == len(b)
+		case a[i] == "**":
+			r = includes(i+1, j) || (j < len(b) && !isVerbatimChunk(b[j]) && includes(i, j+1))
+		case j == len(b) || b[j] == "**":
+			r = false
+		default:
+			r = chunkIncludes(a[i], b[j]) && includes(i+1, j+1)
 		}
-		return true
+		memo[[2]int{i, j}] = r
+		return r
 	}
-	for i := 0; i < dWildIdx; i++ {
-		if i >= len(key) || !matchWildSegment(pattern[i], key[i]) {
-			return false
+	return includes(0, 0)
+}
+
+// keyIntersectsPattern is the pure-Go counterpart of z_keyexpr_intersects on
+// the chunks of two canon key expressions.
+func keyIntersectsPattern(a, b []string) bool {
+	memo := make(map[[2]int]bool)
+	var intersects func(i, j int) bool
+	intersects = func(i, j int) bool {
+		if r, ok := memo[[2]int{i, j}]; ok {
+			return r
 		}
-	}
-	for i := dWildIdx + 1; i < len(pattern); i++ {
-		segIdx := len(key) - (len(pattern) - i)
-		if segIdx < dWildIdx || !matchWildSegment(pattern[i], key[segIdx]) {
-			return false
+		var r bool
+		switch {
+		case i == len(a) && j == len(b):
+			r = true
+		case i < len(a) && a[i] == "**":
+			r = intersects(i+1, j) || (j < len(b) && !isVerbatimChunk(b[j]) && intersects(i, j+1))
+		case j < len(b) && b[j] == "**":
+			r = intersects(i, j+1) || (i < len(a) && !isVerbatimChunk(a[i]) && intersects(i+1, j))
+		case i == len(a) || j == len(b):
+			r = false
+		default:
+			r = chunkIntersects(a[i], b[j]) && intersects(i+1, j+1)
 		}
+		memo[[2]int{i, j}] = r
+		return r
 	}
-	return true
+	return intersects(0, 0)
 }
 
-func matchWildSegment(pattern, key string) bool {
-	if pattern == "*" || pattern == "**" {
-		return true
-	}
-	return pattern == key
+// isVerbatimChunk reports whether a chunk starts with '@'. Verbatim chunks
+// are only matched by themselves, never by "*" or "**".
+func isVerbatimChunk(chunk string) bool {
+	return strings.HasPrefix(chunk, "@")
 }
 
-func keyIncludesPattern(pattern, other []string) bool {
-	dWildIdx := -1
-	for i, seg := range pattern {
-		if seg == "**" {
-			dWildIdx = i
-			break
-		}
-	}
-	if dWildIdx == -1 {
-		if len(pattern) != len(other) {
-			return false
-		}
-		for i := range pattern {
-			if pattern[i] != "*" && pattern[i] != other[i] {
-				return false
-			}
-		}
+// chunkIncludes reports whether every chunk matched by b is matched by a.
+func chunkIncludes(a, b string) bool {
+	switch {
+	case isVerbatimChunk(a) || isVerbatimChunk(b):
+		return a == b
+	case a == "*":
 		return true
+	case b == "*":
+		return false
 	}
-	for i := 0; i < dWildIdx; i++ {
-		if i >= len(other) {
-			return false
-		}
-		if pattern[i] != "*" && pattern[i] != other[i] {
-			return false
-		}
-	}
-	for i := dWildIdx + 1; i < len(pattern); i++ {
-		segIdx := len(other) - (len(pattern) - i)
-		if segIdx < dWildIdx {
-			continue
-		}
-		if pattern[i] != "*" && pattern[i] != other[segIdx] {
-			return false
-		}
-	}
-	return true
+	return subChunksInclude(subChunkTokens(a), subChunkTokens(b))
 }
 
-func keyIntersectsPattern(a, b []string) bool {
-	aDWild := findDWild(a)
-	bDWild := findDWild(b)
-	if aDWild != -1 && bDWild != -1 {
+// chunkIntersects reports whether some chunk is matched by both a and b.
+func chunkIntersects(a, b string) bool {
+	switch {
+	case isVerbatimChunk(a) || isVerbatimChunk(b):
+		return a == b
+	case a == "*" || b == "*":
 		return true
 	}
-	if aDWild != -1 {
-		return intersectWithDWild(a, b, aDWild)
-	}
-	if bDWild != -1 {
-		return intersectWithDWild(b, a, bDWild)
-	}
-	return intersectNoWildcards(a, b)
+	return subChunksIntersect(subChunkTokens(a), subChunkTokens(b))
 }
 
-func findDWild(seg []string) int {
-	for i, s := range seg {
-		if s == "**" {
-			return i
+// subChunkWild stands for a "$*" sub-chunk wildcard in the tokens returned
+// by subChunkTokens. Key expressions cannot contain NUL, so it never clashes
+// with a literal character.
+const subChunkWild = 0
+
+// subChunkTokens splits a chunk into its characters, with each "$*"
+// replaced by subChunkWild.
+func subChunkTokens(chunk string) []byte {
+	tokens := make([]byte, 0, len(chunk))
+	for i := 0; i < len(chunk); i++ {
+		if chunk[i] == '$' && i+1 < len(chunk) && chunk[i+1] == '*' {
+			tokens = append(tokens, subChunkWild)
+			i++
+			continue
 		}
+		tokens = append(tokens, chunk[i])
 	}
-	return -1
+	return tokens
 }
 
-func intersectWithDWild(wildP, pat []string, wIdx int) bool {
-	for i := 0; i < wIdx; i++ {
-		if i >= len(pat) {
-			return false
-		}
-		if wildP[i] != "*" && wildP[i] != pat[i] {
-			return false
-		}
-	}
-	for i := wIdx + 1; i < len(wildP); i++ {
-		segIdx := len(pat) - (len(wildP) - i)
-		if segIdx < wIdx {
-			continue
+// subChunksInclude reports whether every string matched by the tokens of b
+// is matched by the tokens of a.
+func subChunksInclude(a, b []byte) bool {
+	memo := make(map[[2]int]bool)
+	var includes func(i, j int) bool
+	includes = func(i, j int) bool {
+		if r, ok := memo[[2]int{i, j}]; ok {
+			return r
 		}
-		if wildP[i] != "*" && wildP[i] != pat[segIdx] {
-			return false
+		var r bool
+		switch {
+		case i == len(a):
+			r = j == len(b)
+		case a[i] == subChunkWild:
+			r = includes(i+1, j) || (j < len(b) && includes(i, j+1))
+		case j == len(b) || b[j] == subChunkWild:
+			r = false
+		default:
+			r = a[i] == b[j] && includes(i+1, j+1)
 		}
+		memo[[2]int{i, j}] = r
+		return r
 	}
-	return true
+	return includes(0, 0)
 }
 
-func intersectNoWildcards(a, b []string) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for i := range a {
-		if a[i] != "*" && b[i] != "*" && a[i] != b[i] {
-			return false
+// subChunksIntersect reports whether some string is matched by both the
+// tokens of a and the tokens of b.
+func subChunksIntersect(a, b []byte) bool {
+	memo := make(map[[2]int]bool)
+	var intersects func(i, j int) bool
+	intersects = func(i, j int) bool {
+		if r, ok := memo[[2]int{i, j}]; ok {
+			return r
+		}
+		var r bool
+		switch {
+		case i == len(a) && j == len(b):
+			r = true
+		case i < len(a) && a[i] == subChunkWild:
+			r = intersects(i+1, j) || (j < len(b) && intersects(i, j+1))
+		case j < len(b) && b[j] == subChunkWild:
+			r = intersects(i, j+1) || (i < len(a) && intersects(i+1, j))
+		case i == len(a) || j == len(b):
+			r = false
+		default:
+			r = a[i] == b[j] && intersects(i+1, j+1)
 		}
+		memo[[2]int{i, j}] = r
+		return r
 	}
-	return true
+	return intersects(0, 0)
 }
 
 func (k *KeyExpr) Resolve(session *Session) error {
@@ -321,5 +461,76 @@ func (k *OwnedKeyExpr) Cede() string {
 	if k == nil || k.ptr == 0 {
 		return ""
 	}
-	return ""
+	return k.expr
+}
+
+// DeclareKeyExpr declares a key expression on the session. The routing layer
+// maps a declared key expression to a numeric ID, so messages on it no longer
+// carry the full key expression string, which saves bandwidth on high-rate
+// topics with long paths.
+//
+// The returned OwnedKeyExpr is passed to the KeyExpr variants of the put, get
+// and declare functions, such as PutKeyExpr or DeclareSubscriberKeyExpr, and
+// to DeclarePublisher. It must be undeclared with UndeclareKeyExpr, or
+// dropped, once no operation uses it anymore.
+// This is equivalent to z_declare_keyexpr() in zenoh-c.
+func (s *OwnedSession) DeclareKeyExpr(expr string) (*OwnedKeyExpr, error) {
+	if s == nil || !s.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	if _, err := newKeyExprImpl(expr); err != nil {
+		return nil, err
+	}
+	k, err := cgo.SessionFromOwnedPtr(s.ptr, s.owned).DeclareKeyExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &OwnedKeyExpr{ptr: k.Ptr, owned: k.OwnedPtr(), expr: expr}, nil
+}
+
+// UndeclareKeyExpr undeclares a key expression declared on the session with
+// DeclareKeyExpr and releases its resources. After calling UndeclareKeyExpr,
+// the OwnedKeyExpr is invalidated; it is a no-op if it already is.
+// This is equivalent to z_undeclare_keyexpr() in zenoh-c.
+func (s *OwnedSession) UndeclareKeyExpr(keyExpr *OwnedKeyExpr) error {
+	if s == nil || !s.IsValid() {
+		return ErrInvalidValue
+	}
+	if !keyExpr.IsValid() {
+		return nil
+	}
+	k := cgo.OwnedKeyExprFromOwnedPtr(keyExpr.ptr, keyExpr.owned)
+	keyExpr.ptr = 0
+	keyExpr.owned = nil
+	return cgo.SessionFromOwnedPtr(s.ptr, s.owned).UndeclareKeyExpr(k)
+}
+
+// String returns the key expression as a string.
+func (k *OwnedKeyExpr) String() string {
+	if !k.IsValid() {
+		return ""
+	}
+	return k.expr
+}
+
+// Drop releases the resources of the key expression without undeclaring it;
+// a declared key expression stays declared until its session is closed, see
+// OwnedSession.UndeclareKeyExpr. After calling Drop, the OwnedKeyExpr is
+// invalidated.
+//
+// It is safe to call Drop multiple times; subsequent calls are no-ops.
+// This is equivalent to z_keyexpr_drop() in zenoh-c.
+func (k *OwnedKeyExpr) Drop() error {
+	if !k.IsValid() {
+		return nil
+	}
+	cgo.OwnedKeyExprFromOwnedPtr(k.ptr, k.owned).Drop()
+	k.ptr = 0
+	k.owned = nil
+	log.Print("[zenoh] keyexpr dropped")
+	return nil
+}
+
+func (k *OwnedKeyExpr) toCGO() cgo.KeyExprArg {
+	return cgo.KeyExprArg{Expr: k.expr, Declared: cgo.OwnedKeyExprFromOwnedPtr(k.ptr, k.owned)}
 }
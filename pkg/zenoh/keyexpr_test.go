@@ -1,7 +1,12 @@
 package zenoh
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/wind-c/zenoh-go/internal/cgo"
 )
 
 func TestNewKeyExpr(t *testing.T) {
@@ -55,6 +60,7 @@ func TestJoin(t *testing.T) {
 		{"empty a", "", "example", "example", false},
 		{"empty b", "demo", "", "demo", false},
 		{"both empty", "", "", "", true},
+		{"canonized", "demo/**", "**/example", "demo/**/example", false},
 	}
 
 	for _, tt := range tests {
@@ -83,6 +89,8 @@ func TestCanonize(t *testing.T) {
 		{"multiple slashes", "demo//example", "demo/example", false},
 		{"empty segments", "demo//example//test", "demo/example/test", false},
 		{"just slash", "/", "", false},
+		{"repeated double wildcard", "demo/**/**", "demo/**", false},
+		{"lone sub-chunk wildcard", "demo/$*", "demo/*", false},
 	}
 
 	for _, tt := range tests {
@@ -130,6 +138,8 @@ func TestKeyExprIncludes(t *testing.T) {
 		{"double wildcard includes longer", "demo/**", "demo/test/one/two", true, false},
 		{"different paths no include", "demo/*", "other/*", false, false},
 		{"wildcard includes wildcard", "demo/**", "demo/*", true, false},
+		{"single wildcard does not include double", "demo/*", "demo/**", false, false},
+		{"sub-chunk wildcard", "demo/te$*", "demo/test", true, false},
 	}
 
 	for _, tt := range tests {
@@ -168,7 +178,9 @@ func TestKeyExprIntersects(t *testing.T) {
 		{"double wildcard intersects", "demo/**", "demo/test", true, false},
 		{"different prefixes no intersect", "demo/*", "other/*", false, false},
 		{"different exact no intersect", "demo/one", "demo/two", false, false},
-		{"double wildcard both", "a/**", "b/**", true, false},
+		{"double wildcard both", "a/**", "b/**", false, false},
+		{"double wildcard both overlapping", "a/**", "**/b", true, false},
+		{"verbatim chunk not matched by wildcard", "a/*", "a/@v", false, false},
 	}
 
 	for _, tt := range tests {
@@ -193,6 +205,162 @@ func TestKeyExprIntersects(t *testing.T) {
 	}
 }
 
+func TestKeyExprRelationTo(t *testing.T) {
+	tests := []struct {
+		expr1 string
+		expr2 string
+		want  KeyExprRelation
+	}{
+		{"demo/example", "demo/example", KeyExprRelationEquals},
+		{"demo/**", "demo/example", KeyExprRelationIncludes},
+		{"demo/example", "demo/**", KeyExprRelationIntersects},
+		{"demo/*/one", "demo/two/*", KeyExprRelationIntersects},
+		{"demo/one", "demo/two", KeyExprRelationDisjoint},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr1+" "+tt.expr2, func(t *testing.T) {
+			ke1, _ := NewKeyExpr(tt.expr1)
+			ke2, _ := NewKeyExpr(tt.expr2)
+			got, err := ke1.RelationTo(ke2)
+			if err != nil {
+				t.Fatalf("RelationTo() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RelationTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (*KeyExpr)(nil).RelationTo(&KeyExpr{expr: "demo"}); err != ErrInvalidKeyExpr {
+		t.Errorf("RelationTo() on nil error = %v, want ErrInvalidKeyExpr", err)
+	}
+}
+
+func TestConcat(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       string
+		suffix  string
+		want    string
+		wantErr bool
+	}{
+		{"chunk suffix", "demo/ex", "ample", "demo/example", false},
+		{"new chunk", "demo", "/example", "demo/example", false},
+		{"empty key expression", "", "example", "", true},
+		{"merged wildcards", "demo/*", "*", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Concat(tt.a, tt.suffix)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Concat() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Concat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyExprPatterns(t *testing.T) {
+	tests := []struct {
+		a, b       string
+		includes   bool
+		intersects bool
+	}{
+		{"demo/example", "demo/example", true, true},
+		{"demo/*", "demo/example", true, true},
+		{"demo/*", "demo/**", false, true},
+		{"demo/**", "demo/*/x", true, true},
+		{"demo/**", "demo", true, true},
+		{"a/**", "b/**", false, false},
+		{"a/**/c", "**/b/**", false, true},
+		{"**/a/**/b", "x/a/y/b", true, true},
+		{"**/a/**/b", "x/b/y/a", false, false},
+		{"demo/*", "demo/@v", false, false},
+		{"demo/**", "demo/@v/x", false, false},
+		{"demo/@v/**", "demo/@v/x", true, true},
+		{"demo/ex$*", "demo/example", true, true},
+		{"demo/$*ple", "demo/ex$*", false, true},
+		{"demo/ex$*", "demo/exa$*e", true, true},
+		{"demo/a$*", "demo/b$*", false, false},
+		{"demo/a$*", "demo/*", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			a, b := parseKeySegments(tt.a), parseKeySegments(tt.b)
+			if got := keyIncludesPattern(a, b); got != tt.includes {
+				t.Errorf("keyIncludesPattern() = %v, want %v", got, tt.includes)
+			}
+			if got := keyIntersectsPattern(a, b); got != tt.intersects {
+				t.Errorf("keyIntersectsPattern() = %v, want %v", got, tt.intersects)
+			}
+			if got := keyIntersectsPattern(b, a); got != tt.intersects {
+				t.Errorf("reverse keyIntersectsPattern() = %v, want %v", got, tt.intersects)
+			}
+		})
+	}
+}
+
+// fuzzChunks are the chunks FuzzKeyExprMatcher builds key expressions from.
+var fuzzChunks = []string{"a", "b", "ab", "*", "**", "$*", "a$*", "$*b", "@a", "@b"}
+
+// fuzzKeyExpr builds a key expression with one chunk per byte of data.
+func fuzzKeyExpr(data []byte) string {
+	if len(data) > 8 {
+		data = data[:8]
+	}
+	chunks := make([]string, len(data))
+	for i, c := range data {
+		chunks[i] = fuzzChunks[int(c)%len(fuzzChunks)]
+	}
+	return strings.Join(chunks, "/")
+}
+
+// FuzzKeyExprMatcher checks that the pure-Go matcher agrees with zenoh-c.
+func FuzzKeyExprMatcher(f *testing.F) {
+	if testing.Short() {
+		f.Skip("requires zenoh-c")
+	}
+
+	f.Add([]byte{0, 3}, []byte{0, 1})
+	f.Add([]byte{0, 4}, []byte{1, 4})
+	f.Add([]byte{4, 0, 4}, []byte{1, 0, 1, 0})
+	f.Add([]byte{0, 3}, []byte{0, 8})
+	f.Add([]byte{6}, []byte{7})
+	f.Add([]byte{4, 9}, []byte{0, 9})
+	f.Fuzz(func(t *testing.T, a, b []byte) {
+		if len(a) == 0 || len(b) == 0 {
+			t.Skip()
+		}
+		exprA, errA := cgo.KeyExprCanonize(fuzzKeyExpr(a))
+		exprB, errB := cgo.KeyExprCanonize(fuzzKeyExpr(b))
+		if errA != nil || errB != nil {
+			t.Skip()
+		}
+		segsA, segsB := parseKeySegments(exprA), parseKeySegments(exprB)
+
+		includes, err := cgo.KeyExprIncludes(exprA, exprB)
+		if err != nil {
+			t.Skip()
+		}
+		if got := keyIncludesPattern(segsA, segsB); got != includes {
+			t.Errorf("%s includes %s: Go = %v, zenoh-c = %v", exprA, exprB, got, includes)
+		}
+		intersects, err := cgo.KeyExprIntersects(exprA, exprB)
+		if err != nil {
+			t.Fatalf("KeyExprIntersects() error = %v", err)
+		}
+		if got := keyIntersectsPattern(segsA, segsB); got != intersects {
+			t.Errorf("%s intersects %s: Go = %v, zenoh-c = %v", exprA, exprB, got, intersects)
+		}
+	})
+}
+
 func TestKeyExprString(t *testing.T) {
 	ke, err := NewKeyExpr("demo/example/*")
 	if err != nil {
@@ -245,3 +413,137 @@ func TestKeyExprCede(t *testing.T) {
 		t.Error("Cede() on nil should return empty string")
 	}
 }
+
+func TestOwnedSession_DeclareKeyExpr_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	session := openLoopbackPeer(t, "tcp/127.0.0.1:17458")
+
+	keyExpr, err := session.DeclareKeyExpr("demo/declared/with/a/rather/long/path")
+	if err != nil {
+		t.Fatalf("DeclareKeyExpr() error = %v", err)
+	}
+
+	samples := make(chan Sample, 4)
+	sub, err := DeclareSubscriberKeyExpr(session, keyExpr, func(s Sample) { samples <- s }, nil)
+	if err != nil {
+		t.Fatalf("DeclareSubscriberKeyExpr() error = %v", err)
+	}
+	defer sub.Drop()
+
+	queryable, err := DeclareQueryableKeyExpr(session, keyExpr, func(q Query) {
+		q.Reply(q.KeyExpr(), []byte("from queryable"), nil)
+	}, nil)
+	if err != nil {
+		t.Fatalf("DeclareQueryableKeyExpr() error = %v", err)
+	}
+	defer queryable.Drop()
+
+	pub, err := DeclarePublisher(session, keyExpr)
+	if err != nil {
+		t.Fatalf("DeclarePublisher() error = %v", err)
+	}
+	defer pub.Drop()
+
+	if err := pub.Put([]byte("from publisher"), nil); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := session.PutKeyExpr(keyExpr, []byte("from session"), nil); err != nil {
+		t.Fatalf("PutKeyExpr() error = %v", err)
+	}
+	if err := session.Put(keyExpr.String(), []byte("from string"), nil); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	for _, want := range []string{"from publisher", "from session", "from string"} {
+		select {
+		case s := <-samples:
+			if s.KeyExpr != keyExpr.String() || string(s.Payload) != want {
+				t.Errorf("sample = %s %q, want %s %q", s.KeyExpr, s.Payload, keyExpr.String(), want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no sample %q received", want)
+		}
+	}
+
+	replies := make(chan Reply, 1)
+	done := make(chan struct{})
+	err = GetKeyExpr(session, keyExpr, "", func(r Reply) { replies <- r }, &GetOptions{OnDone: func() { close(done) }})
+	if err != nil {
+		t.Fatalf("GetKeyExpr() error = %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetKeyExpr() did not finish")
+	}
+	select {
+	case r := <-replies:
+		if string(r.Value()) != "from queryable" {
+			t.Errorf("reply = %q, want %q", r.Value(), "from queryable")
+		}
+	default:
+		t.Error("no reply received")
+	}
+
+	if err := session.UndeclareKeyExpr(keyExpr); err != nil {
+		t.Fatalf("UndeclareKeyExpr() error = %v", err)
+	}
+	if err := session.PutKeyExpr(keyExpr, []byte("x"), nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("PutKeyExpr() after UndeclareKeyExpr() error = %v, want ErrInvalidValue", err)
+	}
+	if err := session.UndeclareKeyExpr(keyExpr); err != nil {
+		t.Errorf("second UndeclareKeyExpr() error = %v", err)
+	}
+
+	// Dropping a key expression after its session is safe.
+	other, err := session.DeclareKeyExpr("demo/declared/other")
+	if err != nil {
+		t.Fatalf("DeclareKeyExpr() error = %v", err)
+	}
+	if err := session.Drop(); err != nil {
+		t.Fatalf("session Drop() error = %v", err)
+	}
+	if err := other.Drop(); err != nil {
+		t.Errorf("Drop() after session Drop() error = %v", err)
+	}
+}
+
+func TestKeyExprVariants_Validation(t *testing.T) {
+	session := &OwnedSession{ptr: 1}
+	dropped := &OwnedKeyExpr{}
+	callback := func(Sample) {}
+
+	if err := session.PutKeyExpr(nil, nil, nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("PutKeyExpr(nil) error = %v, want ErrInvalidValue", err)
+	}
+	if err := session.DeleteKeyExpr(dropped, nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("DeleteKeyExpr() error = %v, want ErrInvalidValue", err)
+	}
+	if _, err := DeclarePublisherKeyExpr(session, dropped, nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("DeclarePublisherKeyExpr() error = %v, want ErrInvalidValue", err)
+	}
+	if _, err := DeclareSubscriberKeyExpr(session, dropped, callback, nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("DeclareSubscriberKeyExpr() error = %v, want ErrInvalidValue", err)
+	}
+	if _, err := DeclareQueryableKeyExpr(session, nil, func(Query) {}, nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("DeclareQueryableKeyExpr() error = %v, want ErrInvalidValue", err)
+	}
+	if _, err := DeclareQuerierKeyExpr(nil, dropped, nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("DeclareQuerierKeyExpr() error = %v, want ErrInvalidValue", err)
+	}
+
+	calls := 0
+	opts := &GetOptions{OnDone: func() { calls++ }}
+	if err := GetKeyExpr(session, dropped, "", func(Reply) {}, opts); !errors.Is(err, ErrInvalidValue) || calls != 1 {
+		t.Errorf("GetKeyExpr() error = %v with %d OnDone calls, want ErrInvalidValue with 1", err, calls)
+	}
+
+	if err := (&OwnedSession{}).UndeclareKeyExpr(dropped); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("UndeclareKeyExpr() on invalid session error = %v, want ErrInvalidValue", err)
+	}
+	if err := session.UndeclareKeyExpr(dropped); err != nil {
+		t.Errorf("UndeclareKeyExpr() of a dropped key expression error = %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package zenoh
+
+import (
+	"errors"
+	"time"
+	"unsafe"
+
+	"github.com/wind-c/zenoh-go/internal/cgo"
+)
+
+// OwnedLivelinessToken represents a zenoh liveliness token that owns its resources.
+// While the token is declared, liveliness subscribers on an intersecting key
+// expression consider it alive.
+//
+// # Memory Management
+//
+// The token must be dropped to undeclare it. Liveliness subscribers then
+// receive a DELETE sample for its key expression.
+//
+// Example:
+//
+//	token, err := zenoh.DeclareLivelinessToken(session, "robots/r1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer token.Drop()
+type OwnedLivelinessToken struct {
+	ptr   uintptr
+	owned unsafe.Pointer
+}
+
+// DeclareLivelinessToken declares a liveliness token on the given key expression.
+// This is equivalent to z_liveliness_declare_token() in zenoh-c.
+func DeclareLivelinessToken(session *OwnedSession, keyExpr string) (*OwnedLivelinessToken, error) {
+	if session == nil || !session.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	if keyExpr == "" {
+		return nil, ErrInvalidKeyExpr
+	}
+
+	s := cgo.SessionFromOwnedPtr(session.ptr, session.owned)
+	token, err := s.DeclareLivelinessToken(cgo.KeyExprArg{Expr: keyExpr})
+	if err != nil {
+		return nil, err
+	}
+	return &OwnedLivelinessToken{ptr: token.Ptr, owned: token.OwnedPtr()}, nil
+}
+
+// Drop undeclares the liveliness token.
+// After calling Drop, the OwnedLivelinessToken is invalidated.
+//
+// It is safe to call Drop multiple times; subsequent calls are no-ops.
+func (t *OwnedLivelinessToken) Drop() error {
+	if !t.IsValid() {
+		return nil
+	}
+	token := cgo.LivelinessTokenFromOwnedPtr(t.ptr, t.owned)
+	t.ptr = 0
+	t.owned = nil
+	return token.Undeclare()
+}
+
+// IsValid returns true if the OwnedLivelinessToken is valid.
+func (t *OwnedLivelinessToken) IsValid() bool {
+	return t != nil && t.ptr != 0
+}
+
+// Undeclare is an alias for Drop.
+func (t *OwnedLivelinessToken) Undeclare() error {
+	return t.Drop()
+}
+
+// DeclareLivelinessSubscriber declares a subscriber on the liveliness tokens
+// intersecting keyExpr. The callback receives a sample of kind SampleKindPut
+// when a token appears and of kind SampleKindDelete when it disappears.
+// If history is true, tokens that were already alive are reported as well.
+// This is equivalent to z_liveliness_declare_subscriber() in zenoh-c.
+func DeclareLivelinessSubscriber(session *OwnedSession, keyExpr string, callback SubscriberCallback, history bool) (*OwnedSubscriber, error) {
+	if session == nil || !session.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	if keyExpr == "" {
+		return nil, ErrInvalidKeyExpr
+	}
+	if callback == nil {
+		return nil, errors.New("callback cannot be nil")
+	}
+
+	s := cgo.SessionFromOwnedPtr(session.ptr, session.owned)
+
+	cgoCallback := func(sample cgo.SampleData) {
+		callback(sampleFromCGO(sample))
+	}
+
+	sub, err := s.DeclareLivelinessSubscriber(cgo.KeyExprArg{Expr: keyExpr}, cgoCallback, history)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OwnedSubscriber{ptr: sub.Ptr, owned: sub.OwnedPtr()}, nil
+}
+
+// LivelinessGet queries the liveliness tokens currently alive on keyExpr.
+// Each alive token is delivered as a reply carrying its key expression, and
+// the returned channel is closed once the query finishes.
+// A zero timeout uses the query timeout from the zenoh configuration.
+// This is equivalent to z_liveliness_get() in zenoh-c.
+func LivelinessGet(session *OwnedSession, keyExpr string, timeout time.Duration) (*ReplyChannel, error) {
+	if session == nil || !session.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	if keyExpr == "" {
+		return nil, ErrInvalidKeyExpr
+	}
+
+	ch := NewReplyChannel(16)
+	s := cgo.SessionFromOwnedPtr(session.ptr, session.owned)
+	cb := func(data cgo.QueryReplyData) {
+		ch.Send(replyFromCGO(data))
+	}
+	if err := s.LivelinessGet(cgo.KeyExprArg{Expr: keyExpr}, cb, ch.Close, uint64(timeout.Milliseconds())); err != nil {
+		ch.Close()
+		return nil, err
+	}
+	return ch, nil
+}
@@ -0,0 +1,169 @@
+package zenoh
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeclareLivelinessToken_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		session *OwnedSession
+		keyExpr string
+		wantErr error
+	}{
+		{"nil session", nil, "robots/r1", ErrInvalidValue},
+		{"invalid session", &OwnedSession{ptr: 0}, "robots/r1", ErrInvalidValue},
+		{"empty key expr", &OwnedSession{ptr: 1}, "", ErrInvalidKeyExpr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DeclareLivelinessToken(tt.session, tt.keyExpr)
+			if err != tt.wantErr {
+				t.Errorf("DeclareLivelinessToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeclareLivelinessSubscriber_Validation(t *testing.T) {
+	cb := func(Sample) {}
+	tests := []struct {
+		name     string
+		session  *OwnedSession
+		keyExpr  string
+		callback SubscriberCallback
+	}{
+		{"nil session", nil, "robots/**", cb},
+		{"invalid session", &OwnedSession{ptr: 0}, "robots/**", cb},
+		{"empty key expr", &OwnedSession{ptr: 1}, "", cb},
+		{"nil callback", &OwnedSession{ptr: 1}, "robots/**", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DeclareLivelinessSubscriber(tt.session, tt.keyExpr, tt.callback, false); err == nil {
+				t.Error("DeclareLivelinessSubscriber() should return error")
+			}
+		})
+	}
+}
+
+func TestLivelinessGet_Validation(t *testing.T) {
+	if _, err := LivelinessGet(nil, "robots/**", time.Second); err != ErrInvalidValue {
+		t.Errorf("LivelinessGet(nil) error = %v, want %v", err, ErrInvalidValue)
+	}
+	if _, err := LivelinessGet(&OwnedSession{ptr: 1}, "", time.Second); err != ErrInvalidKeyExpr {
+		t.Errorf("LivelinessGet(empty) error = %v, want %v", err, ErrInvalidKeyExpr)
+	}
+}
+
+func TestOwnedLivelinessToken_Drop(t *testing.T) {
+	var token *OwnedLivelinessToken
+	if err := token.Drop(); err != nil {
+		t.Errorf("Drop() on nil token error = %v", err)
+	}
+	if token.IsValid() {
+		t.Error("nil token should not be valid")
+	}
+}
+
+func TestLiveliness_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	session := openLoopbackPeer(t, "tcp/127.0.0.1:17451")
+	defer session.Drop()
+
+	samples := make(chan Sample, 4)
+	sub, err := DeclareLivelinessSubscriber(session, "robots/**", func(s Sample) {
+		samples <- s
+	}, false)
+	if err != nil {
+		t.Fatalf("DeclareLivelinessSubscriber() error = %v", err)
+	}
+	defer sub.Drop()
+
+	token, err := DeclareLivelinessToken(session, "robots/r1")
+	if err != nil {
+		t.Fatalf("DeclareLivelinessToken() error = %v", err)
+	}
+
+	expectSample := func(kind SampleKind) {
+		t.Helper()
+		select {
+		case s := <-samples:
+			if s.KeyExpr != "robots/r1" || s.Kind != kind {
+				t.Errorf("sample = %s %s, want %s robots/r1", s.Kind, s.KeyExpr, kind)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s sample received", kind)
+		}
+	}
+	expectSample(SampleKindPut)
+
+	ch, err := LivelinessGet(session, "robots/**", time.Second)
+	if err != nil {
+		t.Fatalf("LivelinessGet() error = %v", err)
+	}
+	var alive []string
+	for reply := range ch.Chan() {
+		alive = append(alive, reply.KeyExpr())
+	}
+	if len(alive) != 1 || alive[0] != "robots/r1" {
+		t.Errorf("LivelinessGet() = %v, want [robots/r1]", alive)
+	}
+
+	if err := token.Drop(); err != nil {
+		t.Fatalf("Drop() error = %v", err)
+	}
+	expectSample(SampleKindDelete)
+}
+
+func TestLivelinessSubscriber_Drop_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	session := openLoopbackPeer(t, "tcp/127.0.0.1:17459")
+	defer session.Drop()
+
+	samples := make(chan Sample, 4)
+	sub, err := DeclareLivelinessSubscriber(session, "fleet/**", func(s Sample) {
+		samples <- s
+	}, false)
+	if err != nil {
+		t.Fatalf("DeclareLivelinessSubscriber() error = %v", err)
+	}
+
+	first, err := DeclareLivelinessToken(session, "fleet/r1")
+	if err != nil {
+		t.Fatalf("DeclareLivelinessToken() error = %v", err)
+	}
+	defer first.Drop()
+	select {
+	case <-samples:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no sample received before Drop()")
+	}
+
+	if err := sub.Drop(); err != nil {
+		t.Fatalf("Drop() error = %v", err)
+	}
+	if sub.IsValid() {
+		t.Error("subscriber should not be valid after Drop()")
+	}
+
+	second, err := DeclareLivelinessToken(session, "fleet/r2")
+	if err != nil {
+		t.Fatalf("DeclareLivelinessToken() error = %v", err)
+	}
+	defer second.Drop()
+	select {
+	case s := <-samples:
+		t.Errorf("sample %s %s received after Drop()", s.Kind, s.KeyExpr)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
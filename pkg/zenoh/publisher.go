@@ -2,6 +2,7 @@ package zenoh
 
 import (
 	"errors"
+	"unsafe"
 
 	"github.com/wind-c/zenoh-go/internal/cgo"
 )
@@ -12,14 +13,23 @@ type MatchingStatus struct {
 	Matched bool
 }
 
+// DeclarePublisher declares a publisher on a key expression declared with
+// OwnedSession.DeclareKeyExpr, so that its publications carry the numeric ID
+// of the key expression.
 func DeclarePublisher(session *OwnedSession, keyExpr *OwnedKeyExpr) (*OwnedPublisher, error) {
+	return DeclarePublisherKeyExpr(session, keyExpr, nil)
+}
+
+// DeclarePublisherKeyExpr is like DeclarePublisher, with custom options.
+// A nil opts keeps the zenoh defaults.
+func DeclarePublisherKeyExpr(session *OwnedSession, keyExpr *OwnedKeyExpr, opts *PublisherOptions) (*OwnedPublisher, error) {
 	if session == nil || !session.IsValid() {
 		return nil, ErrInvalidValue
 	}
-	if keyExpr == nil || !keyExpr.IsValid() {
+	if !keyExpr.IsValid() {
 		return nil, ErrInvalidValue
 	}
-	return nil, errors.New("DeclarePublisher requires OwnedKeyExpr with string representation")
+	return declarePublisher(session, keyExpr.toCGO(), opts)
 }
 
 func DeclarePublisherWithKeyExpr(session *OwnedSession, keyExpr string) (*OwnedPublisher, error) {
@@ -29,12 +39,7 @@ func DeclarePublisherWithKeyExpr(session *OwnedSession, keyExpr string) (*OwnedP
 	if keyExpr == "" {
 		return nil, ErrInvalidKeyExpr
 	}
-	s := cgo.SessionFromOwnedPtr(session.ptr, session.owned)
-	p, err := s.DeclarePublisherByKeyExpr(keyExpr)
-	if err != nil {
-		return nil, err
-	}
-	return &OwnedPublisher{ptr: p.Ptr}, nil
+	return declarePublisher(session, cgo.KeyExprArg{Expr: keyExpr}, nil)
 }
 
 // PublisherOptions contains options for Publisher declaration.
@@ -43,13 +48,21 @@ type PublisherOptions struct {
 	Reliability Reliability
 	// CongestionControl specifies the congestion control mode.
 	CongestionControl CongestionControl
+	// Priority specifies the priority of the publications. Zero selects PriorityDefault.
+	Priority Priority
+	// IsExpress disables batching for the publications, trading throughput for latency.
+	IsExpress bool
+	// AllowedDestination restricts the sessions the publications may reach.
+	AllowedDestination Locality
 }
 
 // DefaultPublisherOptions returns default publisher options.
 func DefaultPublisherOptions() *PublisherOptions {
 	return &PublisherOptions{
-		Reliability:       ReliabilityBestEffort,
-		CongestionControl: CongestionControlDrop,
+		Reliability:        ReliabilityBestEffort,
+		CongestionControl:  CongestionControlDrop,
+		Priority:           PriorityDefault,
+		AllowedDestination: LocalityAny,
 	}
 }
 
@@ -64,8 +77,27 @@ func DeclarePublisherWithOptions(session *OwnedSession, keyExpr string, opts *Pu
 	if opts == nil {
 		opts = DefaultPublisherOptions()
 	}
+	return declarePublisher(session, cgo.KeyExprArg{Expr: keyExpr}, opts)
+}
+
+// declarePublisher declares a publisher on keyExpr. A nil opts keeps the
+// zenoh defaults.
+func declarePublisher(session *OwnedSession, keyExpr cgo.KeyExprArg, opts *PublisherOptions) (*OwnedPublisher, error) {
 	s := cgo.SessionFromOwnedPtr(session.ptr, session.owned)
-	p, err := s.DeclarePublisherByKeyExprWithOptions(keyExpr, int(opts.Reliability), int(opts.CongestionControl))
+	if opts == nil {
+		p, err := s.DeclarePublisherByKeyExpr(keyExpr)
+		if err != nil {
+			return nil, err
+		}
+		return &OwnedPublisher{ptr: p.Ptr}, nil
+	}
+	p, err := s.DeclarePublisherByKeyExprWithOptions(keyExpr, &cgo.PublisherOptions{
+		Reliability:        int(opts.Reliability),
+		CongestionControl:  int(opts.CongestionControl),
+		Priority:           int(opts.Priority),
+		IsExpress:          opts.IsExpress,
+		AllowedDestination: int(opts.AllowedDestination),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -81,12 +113,43 @@ func (p *OwnedPublisher) Put(data []byte, encoding *Encoding) error {
 	return pub.Put(data, enc)
 }
 
+// PublisherPutOptions contains options for Publisher put operations.
+type PublisherPutOptions struct {
+	// Encoding specifies the encoding of the payload.
+	Encoding *Encoding
+	// Attachment is optional user metadata sent alongside the payload.
+	Attachment *Attachment
+	// Timestamp, if set, is sent instead of a timestamp from the session clock.
+	Timestamp *ZenohTimestamp
+}
+
+// PutWithOptions publishes data with custom options. Unlike Put, an empty
+// payload is published as well.
+// This is equivalent to z_publisher_put() in zenoh-c.
+func (p *OwnedPublisher) PutWithOptions(data []byte, opts *PublisherPutOptions) error {
+	if p == nil || p.ptr == 0 {
+		return ErrInvalidPublisher
+	}
+	return publisherPutWithOptions(p.ptr, data, opts)
+}
+
+func publisherPutWithOptions(ptr uintptr, data []byte, opts *PublisherPutOptions) error {
+	var cgoOpts *cgo.PublisherPutOptions
+	if opts != nil {
+		cgoOpts = &cgo.PublisherPutOptions{
+			Encoding:   opts.Encoding.toCGO(),
+			Attachment: opts.Attachment.toCGO(),
+			Timestamp:  opts.Timestamp.toCGO(),
+		}
+	}
+	return cgo.PublisherFromPtr(ptr).PutWithOptions(data, cgoOpts)
+}
+
 func (p *OwnedPublisher) Delete() error {
 	if p == nil || p.ptr == 0 {
 		return ErrInvalidPublisher
 	}
-	pub := cgo.Publisher{Ptr: p.ptr}
-	return pub.Delete()
+	return cgo.PublisherFromPtr(p.ptr).Delete()
 }
 
 func (p *OwnedPublisher) Undeclare() error {
@@ -106,7 +169,7 @@ func (p *OwnedPublisher) MatchingStatus() (*MatchingStatus, error) {
 	if p == nil || p.ptr == 0 {
 		return nil, ErrInvalidPublisher
 	}
-	pub := cgo.Publisher{Ptr: p.ptr}
+	pub := cgo.PublisherFromPtr(p.ptr)
 	matched, err := pub.MatchingStatus()
 	if err != nil {
 		return nil, err
@@ -116,6 +179,99 @@ func (p *OwnedPublisher) MatchingStatus() (*MatchingStatus, error) {
 	}, nil
 }
 
+// MatchingCallback is a function type that handles matching status changes.
+type MatchingCallback func(status MatchingStatus)
+
+// OwnedMatchingListener represents a zenoh matching listener that owns its resources.
+// It must be dropped to stop receiving matching status changes.
+type OwnedMatchingListener struct {
+	owned unsafe.Pointer
+}
+
+// DeclareMatchingListener declares a listener that is called whenever the
+// publisher gains its first matching subscriber (Matched is true) or loses
+// its last one (Matched is false).
+// This is equivalent to z_publisher_declare_matching_listener() in zenoh-c.
+func (p *OwnedPublisher) DeclareMatchingListener(callback MatchingCallback) (*OwnedMatchingListener, error) {
+	if p == nil || p.ptr == 0 {
+		return nil, ErrInvalidPublisher
+	}
+	if callback == nil {
+		return nil, errors.New("callback cannot be nil")
+	}
+	cgoCallback := func(matching bool) {
+		callback(MatchingStatus{Matched: matching})
+	}
+	listener, err := cgo.PublisherFromPtr(p.ptr).DeclareMatchingListener(cgoCallback, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &OwnedMatchingListener{owned: listener.OwnedPtr()}, nil
+}
+
+// DeclareMatchingListenerWithChannel is like DeclareMatchingListener but
+// delivers matching status changes on a channel, which is closed once the
+// listener is dropped. When the channel is full, the oldest status is
+// discarded so that the latest one is always delivered.
+func (p *OwnedPublisher) DeclareMatchingListenerWithChannel(bufferSize int) (*OwnedMatchingListener, <-chan MatchingStatus, error) {
+	if p == nil || p.ptr == 0 {
+		return nil, nil, ErrInvalidPublisher
+	}
+	ch, send := newMatchingChannel(bufferSize)
+	listener, err := cgo.PublisherFromPtr(p.ptr).DeclareMatchingListener(send, func() { close(ch) })
+	if err != nil {
+		return nil, nil, err
+	}
+	return &OwnedMatchingListener{owned: listener.OwnedPtr()}, ch, nil
+}
+
+// newMatchingChannel returns a channel of matching statuses and a callback
+// feeding it that never blocks, discarding the oldest status when full.
+func newMatchingChannel(bufferSize int) (chan MatchingStatus, cgo.MatchingStatusCallback) {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	ch := make(chan MatchingStatus, bufferSize)
+	send := func(matching bool) {
+		status := MatchingStatus{Matched: matching}
+		for {
+			select {
+			case ch <- status:
+				return
+			default:
+				select {
+				case <-ch:
+				default:
+				}
+			}
+		}
+	}
+	return ch, send
+}
+
+// Drop undeclares the matching listener.
+// After calling Drop, the OwnedMatchingListener is invalidated.
+//
+// It is safe to call Drop multiple times; subsequent calls are no-ops.
+func (l *OwnedMatchingListener) Drop() error {
+	if !l.IsValid() {
+		return nil
+	}
+	listener := cgo.MatchingListenerFromOwnedPtr(l.owned)
+	l.owned = nil
+	return listener.Undeclare()
+}
+
+// IsValid returns true if the OwnedMatchingListener is valid.
+func (l *OwnedMatchingListener) IsValid() bool {
+	return l != nil && l.owned != nil
+}
+
+// Undeclare is an alias for Drop.
+func (l *OwnedMatchingListener) Undeclare() error {
+	return l.Drop()
+}
+
 func FromOwnedPublisher(owned *OwnedPublisher) (*Publisher, error) {
 	if owned == nil || !owned.IsValid() {
 		return nil, ErrInvalidPublisher
@@ -127,17 +283,25 @@ func (p *Publisher) Put(data []byte, encoding *Encoding) error {
 	if p == nil || p.ptr == 0 {
 		return ErrInvalidPublisher
 	}
-	pub := cgo.Publisher{Ptr: p.ptr}
+	pub := cgo.PublisherFromPtr(p.ptr)
 	enc := encoding.toCGO()
 	return pub.Put(data, enc)
 }
 
+// PutWithOptions publishes data with custom options. Unlike Put, an empty
+// payload is published as well.
+func (p *Publisher) PutWithOptions(data []byte, opts *PublisherPutOptions) error {
+	if p == nil || p.ptr == 0 {
+		return ErrInvalidPublisher
+	}
+	return publisherPutWithOptions(p.ptr, data, opts)
+}
+
 func (p *Publisher) Delete() error {
 	if p == nil || p.ptr == 0 {
 		return ErrInvalidPublisher
 	}
-	pub := cgo.Publisher{Ptr: p.ptr}
-	return pub.Delete()
+	return cgo.PublisherFromPtr(p.ptr).Delete()
 }
 
 func (p *Publisher) MatchingStatus() (*MatchingStatus, error) {
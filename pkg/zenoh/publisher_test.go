@@ -3,6 +3,7 @@ package zenoh
 import (
 	"errors"
 	"testing"
+	"time"
 )
 
 func TestDeclarePublisherWithKeyExpr(t *testing.T) {
@@ -207,6 +208,15 @@ func TestPublisherOptions_Default(t *testing.T) {
 	if opts.CongestionControl != CongestionControlDrop {
 		t.Errorf("Expected CongestionControl=CongestionControlDrop, got %v", opts.CongestionControl)
 	}
+	if opts.Priority != PriorityDefault {
+		t.Errorf("Expected Priority=PriorityDefault, got %v", opts.Priority)
+	}
+	if opts.IsExpress {
+		t.Error("Expected IsExpress=false")
+	}
+	if opts.AllowedDestination != LocalityAny {
+		t.Errorf("Expected AllowedDestination=LocalityAny, got %v", opts.AllowedDestination)
+	}
 }
 
 func TestPublisherOptions_Custom(t *testing.T) {
@@ -221,3 +231,93 @@ func TestPublisherOptions_Custom(t *testing.T) {
 		t.Errorf("Expected CongestionControl=CongestionControlBlock, got %v", opts.CongestionControl)
 	}
 }
+
+func TestOwnedPublisher_DeclareMatchingListener_Validation(t *testing.T) {
+	cb := func(MatchingStatus) {}
+
+	var nilPub *OwnedPublisher
+	if _, err := nilPub.DeclareMatchingListener(cb); !errors.Is(err, ErrInvalidPublisher) {
+		t.Errorf("DeclareMatchingListener() on nil publisher error = %v, want ErrInvalidPublisher", err)
+	}
+	if _, err := (&OwnedPublisher{}).DeclareMatchingListener(cb); !errors.Is(err, ErrInvalidPublisher) {
+		t.Errorf("DeclareMatchingListener() on invalid publisher error = %v, want ErrInvalidPublisher", err)
+	}
+	if _, err := (&OwnedPublisher{ptr: 1}).DeclareMatchingListener(nil); err == nil {
+		t.Error("DeclareMatchingListener() with nil callback should return error")
+	}
+	if _, _, err := (&OwnedPublisher{}).DeclareMatchingListenerWithChannel(1); !errors.Is(err, ErrInvalidPublisher) {
+		t.Errorf("DeclareMatchingListenerWithChannel() on invalid publisher error = %v, want ErrInvalidPublisher", err)
+	}
+}
+
+func TestNewMatchingChannel_KeepsLatest(t *testing.T) {
+	ch, send := newMatchingChannel(1)
+	send(true)
+	send(false)
+
+	select {
+	case status := <-ch:
+		if status.Matched {
+			t.Error("expected the latest status (Matched=false)")
+		}
+	default:
+		t.Fatal("expected a status on the channel")
+	}
+}
+
+func TestOwnedMatchingListener_Drop(t *testing.T) {
+	var l *OwnedMatchingListener
+	if err := l.Drop(); err != nil {
+		t.Errorf("Drop() on nil listener error = %v", err)
+	}
+	if l.IsValid() {
+		t.Error("nil listener should not be valid")
+	}
+}
+
+func TestOwnedPublisher_MatchingListener_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	session := openLoopbackPeer(t, "tcp/127.0.0.1:17452")
+	defer session.Drop()
+
+	pub, err := DeclarePublisherWithKeyExpr(session, "demo/matching/test")
+	if err != nil {
+		t.Fatalf("DeclarePublisherWithKeyExpr() error = %v", err)
+	}
+	defer pub.Drop()
+
+	listener, statuses, err := pub.DeclareMatchingListenerWithChannel(4)
+	if err != nil {
+		t.Fatalf("DeclareMatchingListenerWithChannel() error = %v", err)
+	}
+
+	sub, err := DeclareSubscriber(session, "demo/matching/**", func(Sample) {})
+	if err != nil {
+		t.Fatalf("DeclareSubscriber() error = %v", err)
+	}
+	defer sub.Drop()
+
+	select {
+	case status := <-statuses:
+		if !status.Matched {
+			t.Error("expected Matched=true once a subscriber is declared")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no matching status received")
+	}
+
+	if err := listener.Drop(); err != nil {
+		t.Fatalf("Drop() error = %v", err)
+	}
+	select {
+	case _, ok := <-statuses:
+		for ok {
+			_, ok = <-statuses
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel not closed after Drop()")
+	}
+}
@@ -0,0 +1,226 @@
+package zenoh
+
+import (
+	"errors"
+	"time"
+	"unsafe"
+
+	"github.com/wind-c/zenoh-go/internal/cgo"
+)
+
+var ErrInvalidQuerier = errors.New("invalid querier")
+
+// QuerierOptions contains options for Querier declaration.
+// They apply to every query sent through the querier.
+type QuerierOptions struct {
+	// Target specifies the query target.
+	Target QueryTarget
+	// Consolidation specifies the consolidation mode.
+	Consolidation Consolidation
+	// Timeout specifies the query timeout.
+	// If 0, the timeout from the session configuration is used.
+	Timeout time.Duration
+	// CongestionControl specifies the congestion control mode.
+	CongestionControl CongestionControl
+	// Priority specifies the priority of the queries. Zero selects PriorityDefault.
+	Priority Priority
+	// IsExpress disables batching for the queries, trading throughput for latency.
+	IsExpress bool
+	// AllowedDestination restricts the queryables the queries may reach.
+	AllowedDestination Locality
+}
+
+// DefaultQuerierOptions returns default querier options.
+func DefaultQuerierOptions() *QuerierOptions {
+	return &QuerierOptions{
+		Target:             QueryTargetBestMatching,
+		Consolidation:      ConsolidationAuto,
+		CongestionControl:  CongestionControlBlock,
+		Priority:           PriorityDefault,
+		AllowedDestination: LocalityAny,
+	}
+}
+
+// OwnedQuerier represents a zenoh querier that owns its resources.
+// A querier sends repeated queries on the same key expression without
+// resolving it and its options on every query.
+//
+// Example:
+//
+//	querier, err := zenoh.DeclareQuerier(session, "robot/*/status", nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer querier.Drop()
+//	querier.Get("", nil, func(reply zenoh.Reply) { ... })
+type OwnedQuerier struct {
+	ptr   uintptr
+	owned unsafe.Pointer
+}
+
+// DeclareQuerier declares a querier on the given key expression.
+// This is equivalent to z_declare_querier() in zenoh-c.
+func DeclareQuerier(session *OwnedSession, keyExpr string, opts *QuerierOptions) (*OwnedQuerier, error) {
+	if session == nil || !session.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	if keyExpr == "" {
+		return nil, ErrInvalidKeyExpr
+	}
+	return declareQuerier(session, cgo.KeyExprArg{Expr: keyExpr}, opts)
+}
+
+// DeclareQuerierKeyExpr is like DeclareQuerier, but on a key expression
+// declared with OwnedSession.DeclareKeyExpr.
+// This is equivalent to z_declare_querier() in zenoh-c.
+func DeclareQuerierKeyExpr(session *OwnedSession, keyExpr *OwnedKeyExpr, opts *QuerierOptions) (*OwnedQuerier, error) {
+	if session == nil || !session.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	if !keyExpr.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	return declareQuerier(session, keyExpr.toCGO(), opts)
+}
+
+func declareQuerier(session *OwnedSession, keyExpr cgo.KeyExprArg, opts *QuerierOptions) (*OwnedQuerier, error) {
+	if opts == nil {
+		opts = DefaultQuerierOptions()
+	}
+
+	s := cgo.SessionFromOwnedPtr(session.ptr, session.owned)
+	q, err := s.DeclareQuerier(keyExpr, &cgo.QuerierOptions{
+		Target:             int(opts.Target),
+		Consolidation:      opts.Consolidation.consolidationMode(),
+		TimeoutMs:          uint64(opts.Timeout.Milliseconds()),
+		CongestionControl:  int(opts.CongestionControl),
+		Priority:           int(opts.Priority),
+		IsExpress:          opts.IsExpress,
+		AllowedDestination: int(opts.AllowedDestination),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &OwnedQuerier{ptr: q.Ptr, owned: q.OwnedPtr()}, nil
+}
+
+// QuerierGetOptions contains options for Querier get operations.
+type QuerierGetOptions struct {
+	// Payload is an optional value sent along with the query.
+	Payload []byte
+	// Encoding specifies the encoding of the query payload.
+	Encoding *Encoding
+	// Attachment is optional user metadata sent along with the query.
+	Attachment *Attachment
+	// OnDone, if set, is called once after the last reply has been delivered,
+	// either because all queryables answered or because the timeout expired.
+	// It is also called, before the error is returned, if the query could not
+	// be sent.
+	OnDone func()
+}
+
+// Get sends a query with the given selector parameters and an optional
+// payload. The handler is called for every reply.
+// This is equivalent to z_querier_get() in zenoh-c.
+func (q *OwnedQuerier) Get(params string, payload []byte, handler ReplyCallback) error {
+	return q.GetWithOptions(params, handler, &QuerierGetOptions{Payload: payload})
+}
+
+// GetWithOptions sends a query with the given selector parameters and custom options.
+// This is equivalent to z_querier_get() in zenoh-c.
+func (q *OwnedQuerier) GetWithOptions(params string, handler ReplyCallback, opts *QuerierGetOptions) error {
+	var cgoOpts *cgo.QuerierGetOptions
+	var done func()
+	if opts != nil {
+		cgoOpts = &cgo.QuerierGetOptions{
+			Payload:    opts.Payload,
+			Encoding:   opts.Encoding.toCGO(),
+			Attachment: opts.Attachment.toCGO(),
+		}
+		done = opts.OnDone
+	}
+	if !q.IsValid() {
+		return queryNotSent(done, ErrInvalidQuerier)
+	}
+	if handler == nil {
+		return queryNotSent(done, errors.New("handler cannot be nil"))
+	}
+	cb := func(data cgo.QueryReplyData) {
+		handler(replyFromCGO(data))
+	}
+	return cgo.QuerierFromOwnedPtr(q.ptr, q.owned).Get(params, cb, done, cgoOpts)
+}
+
+// MatchingStatus reports whether any queryable currently matches the querier.
+// This is equivalent to z_querier_get_matching_status() in zenoh-c.
+func (q *OwnedQuerier) MatchingStatus() (*MatchingStatus, error) {
+	if !q.IsValid() {
+		return nil, ErrInvalidQuerier
+	}
+	matched, err := cgo.QuerierFromOwnedPtr(q.ptr, q.owned).MatchingStatus()
+	if err != nil {
+		return nil, err
+	}
+	return &MatchingStatus{Matched: matched}, nil
+}
+
+// DeclareMatchingListener declares a listener that is called whenever the
+// querier gains its first matching queryable (Matched is true) or loses
+// its last one (Matched is false).
+// This is equivalent to z_querier_declare_matching_listener() in zenoh-c.
+func (q *OwnedQuerier) DeclareMatchingListener(callback MatchingCallback) (*OwnedMatchingListener, error) {
+	if !q.IsValid() {
+		return nil, ErrInvalidQuerier
+	}
+	if callback == nil {
+		return nil, errors.New("callback cannot be nil")
+	}
+	cgoCallback := func(matching bool) {
+		callback(MatchingStatus{Matched: matching})
+	}
+	listener, err := cgo.QuerierFromOwnedPtr(q.ptr, q.owned).DeclareMatchingListener(cgoCallback, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &OwnedMatchingListener{owned: listener.OwnedPtr()}, nil
+}
+
+// DeclareMatchingListenerWithChannel is like DeclareMatchingListener but
+// delivers matching status changes on a channel, which is closed once the
+// listener is dropped. When the channel is full, the oldest status is
+// discarded so that the latest one is always delivered.
+func (q *OwnedQuerier) DeclareMatchingListenerWithChannel(bufferSize int) (*OwnedMatchingListener, <-chan MatchingStatus, error) {
+	if !q.IsValid() {
+		return nil, nil, ErrInvalidQuerier
+	}
+	ch, send := newMatchingChannel(bufferSize)
+	listener, err := cgo.QuerierFromOwnedPtr(q.ptr, q.owned).DeclareMatchingListener(send, func() { close(ch) })
+	if err != nil {
+		return nil, nil, err
+	}
+	return &OwnedMatchingListener{owned: listener.OwnedPtr()}, ch, nil
+}
+
+// Drop undeclares the querier. Queries already sent still receive their replies.
+// After calling Drop, the OwnedQuerier is invalidated.
+//
+// It is safe to call Drop multiple times; subsequent calls are no-ops.
+func (q *OwnedQuerier) Drop() error {
+	if !q.IsValid() {
+		return nil
+	}
+	querier := cgo.QuerierFromOwnedPtr(q.ptr, q.owned)
+	q.ptr = 0
+	q.owned = nil
+	return querier.Undeclare()
+}
+
+// IsValid returns true if the OwnedQuerier is valid.
+func (q *OwnedQuerier) IsValid() bool {
+	return q != nil && q.ptr != 0
+}
+
+// Undeclare is an alias for Drop.
+func (q *OwnedQuerier) Undeclare() error {
+	return q.Drop()
+}
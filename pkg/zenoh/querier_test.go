@@ -0,0 +1,147 @@
+package zenoh
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeclareQuerier(t *testing.T) {
+	tests := []struct {
+		name    string
+		session *OwnedSession
+		keyExpr string
+		wantErr error
+	}{
+		{"nil session", nil, "demo/test", ErrInvalidValue},
+		{"invalid session", &OwnedSession{ptr: 0}, "demo/test", ErrInvalidValue},
+		{"empty keyExpr", &OwnedSession{ptr: 1}, "", ErrInvalidKeyExpr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DeclareQuerier(tt.session, tt.keyExpr, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeclareQuerier() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQuerierOptions_Default(t *testing.T) {
+	opts := DefaultQuerierOptions()
+	if opts.Target != QueryTargetBestMatching {
+		t.Errorf("Expected Target=QueryTargetBestMatching, got %v", opts.Target)
+	}
+	if opts.Consolidation != ConsolidationAuto {
+		t.Errorf("Expected Consolidation=ConsolidationAuto, got %v", opts.Consolidation)
+	}
+	if opts.Timeout != 0 {
+		t.Errorf("Expected Timeout=0, got %v", opts.Timeout)
+	}
+	if opts.CongestionControl != CongestionControlBlock {
+		t.Errorf("Expected CongestionControl=CongestionControlBlock, got %v", opts.CongestionControl)
+	}
+	if opts.Priority != PriorityDefault {
+		t.Errorf("Expected Priority=PriorityDefault, got %v", opts.Priority)
+	}
+	if opts.AllowedDestination != LocalityAny {
+		t.Errorf("Expected AllowedDestination=LocalityAny, got %v", opts.AllowedDestination)
+	}
+}
+
+func TestOwnedQuerier_Validation(t *testing.T) {
+	handler := func(Reply) {}
+
+	for _, q := range []*OwnedQuerier{nil, {ptr: 0}} {
+		if err := q.Get("", nil, handler); !errors.Is(err, ErrInvalidQuerier) {
+			t.Errorf("Get() error = %v, want ErrInvalidQuerier", err)
+		}
+		calls := 0
+		if err := q.GetWithOptions("", handler, &QuerierGetOptions{OnDone: func() { calls++ }}); err == nil || calls != 1 {
+			t.Errorf("GetWithOptions() error = %v, OnDone calls = %d, want an error and 1 call", err, calls)
+		}
+		if _, err := q.MatchingStatus(); !errors.Is(err, ErrInvalidQuerier) {
+			t.Errorf("MatchingStatus() error = %v, want ErrInvalidQuerier", err)
+		}
+		if _, err := q.DeclareMatchingListener(func(MatchingStatus) {}); !errors.Is(err, ErrInvalidQuerier) {
+			t.Errorf("DeclareMatchingListener() error = %v, want ErrInvalidQuerier", err)
+		}
+		if _, _, err := q.DeclareMatchingListenerWithChannel(1); !errors.Is(err, ErrInvalidQuerier) {
+			t.Errorf("DeclareMatchingListenerWithChannel() error = %v, want ErrInvalidQuerier", err)
+		}
+		if err := q.Drop(); err != nil {
+			t.Errorf("Drop() error = %v, want nil", err)
+		}
+		if q.IsValid() {
+			t.Error("querier should not be valid")
+		}
+	}
+
+	if err := (&OwnedQuerier{ptr: 1}).Get("", nil, nil); err == nil {
+		t.Error("Get() with nil handler should return error")
+	}
+}
+
+func TestOwnedQuerier_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	session := openLoopbackPeer(t, "tcp/127.0.0.1:17453")
+	defer session.Drop()
+
+	opts := DefaultQuerierOptions()
+	opts.Target = QueryTargetAll
+	opts.Timeout = time.Second
+	querier, err := DeclareQuerier(session, "demo/querier/**", opts)
+	if err != nil {
+		t.Fatalf("DeclareQuerier() error = %v", err)
+	}
+	defer querier.Drop()
+
+	listener, statuses, err := querier.DeclareMatchingListenerWithChannel(4)
+	if err != nil {
+		t.Fatalf("DeclareMatchingListenerWithChannel() error = %v", err)
+	}
+	defer listener.Drop()
+
+	queryable, err := DeclareQueryable(session, "demo/querier/echo", func(q Query) {
+		q.Reply(q.KeyExpr(), append([]byte(q.Parameters()+":"), q.Value()...), nil)
+	})
+	if err != nil {
+		t.Fatalf("DeclareQueryable() error = %v", err)
+	}
+	defer queryable.Drop()
+
+	select {
+	case status := <-statuses:
+		if !status.Matched {
+			t.Error("expected Matched=true once a queryable is declared")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no matching status received")
+	}
+
+	replies := make(chan Reply, 4)
+	done := make(chan struct{})
+	err = querier.GetWithOptions("n=1", func(r Reply) { replies <- r }, &QuerierGetOptions{
+		Payload: []byte("ping"),
+		OnDone:  func() { close(done) },
+	})
+	if err != nil {
+		t.Fatalf("GetWithOptions() error = %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("query did not finish")
+	}
+	if len(replies) != 1 {
+		t.Fatalf("got %d replies, want 1", len(replies))
+	}
+	if reply := <-replies; string(reply.Value()) != "n=1:ping" {
+		t.Errorf("reply = %q, want %q", reply.Value(), "n=1:ping")
+	}
+}
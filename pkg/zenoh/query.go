@@ -1,8 +1,11 @@
 package zenoh
 
 import (
+	"context"
 	"errors"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/wind-c/zenoh-go/internal/cgo"
 )
@@ -10,13 +13,15 @@ import (
 var ErrInvalidSelector = errors.New("invalid selector")
 
 type Reply struct {
-	keyExpr  string
-	value    []byte
-	encoding *Encoding
-	isOk     bool
-	errMsg   string
-	senderID []byte
-	ptr      uintptr
+	keyExpr    string
+	kind       SampleKind
+	value      []byte
+	encoding   *Encoding
+	attachment *Attachment
+	isOk       bool
+	errMsg     string
+	senderID   []byte
+	ptr        uintptr
 }
 
 func (r *Reply) KeyExpr() string {
@@ -26,6 +31,15 @@ func (r *Reply) KeyExpr() string {
 	return r.keyExpr
 }
 
+// Kind returns SampleKindDelete for a reply sent with Query.ReplyDelete
+// and SampleKindPut otherwise.
+func (r *Reply) Kind() SampleKind {
+	if r == nil {
+		return SampleKindPut
+	}
+	return r.kind
+}
+
 func (r *Reply) Value() []byte {
 	if r == nil {
 		return nil
@@ -40,6 +54,14 @@ func (r *Reply) Encoding() *Encoding {
 	return r.encoding
 }
 
+// Attachment returns the attachment of the reply, or nil if it carries none.
+func (r *Reply) Attachment() *Attachment {
+	if r == nil {
+		return nil
+	}
+	return r.attachment
+}
+
 func (r *Reply) IsOk() bool {
 	if r == nil {
 		return false
@@ -78,7 +100,10 @@ type replyClosure struct {
 	channel  *ReplyChannel
 }
 
+// ReplyChannel buffers the replies of a query. It is closed by the query once
+// the last reply has been delivered, so ranging over Chan() terminates.
 type ReplyChannel struct {
+	mu     sync.Mutex
 	ch     chan Reply
 	closed bool
 }
@@ -97,6 +122,8 @@ func (r *ReplyChannel) Chan() <-chan Reply {
 }
 
 func (r *ReplyChannel) Send(reply Reply) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if r.closed {
 		return false
 	}
@@ -115,6 +142,8 @@ func (r *ReplyChannel) Send(reply Reply) bool {
 }
 
 func (r *ReplyChannel) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if !r.closed {
 		r.closed = true
 		close(r.ch)
@@ -122,6 +151,8 @@ func (r *ReplyChannel) Close() {
 }
 
 func (r *ReplyChannel) IsClosed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.closed
 }
 
@@ -144,6 +175,9 @@ func (it *ReplyIterator) Next() bool {
 	}
 	reply, ok := <-it.ch
 	if !ok {
+		it.ch = nil
+		it.current = Reply{}
+		it.valid = false
 		return false
 	}
 	it.current = reply
@@ -171,37 +205,220 @@ func (it *ReplyIterator) Encoding() *Encoding {
 	return it.current.Encoding()
 }
 
+func replyFromCGO(data cgo.QueryReplyData) Reply {
+	reply := Reply{
+		keyExpr: data.KeyExpr,
+		kind:    SampleKind(data.Kind),
+		value:   data.Payload,
+		isOk:    data.Ok,
+		errMsg:  data.ErrMsg,
+		ptr:     0,
+	}
+	if data.Ok {
+		reply.encoding = EncodingFromStr(data.Encoding)
+		reply.attachment = attachmentFromCGO(data.Attachment)
+	} else if data.Encoding != "" {
+		reply.encoding = EncodingFromStr(data.Encoding)
+	}
+	return reply
+}
+
+func (o *GetOptions) toCGO() *cgo.GetOptions {
+	if o == nil {
+		return nil
+	}
+	return &cgo.GetOptions{
+		Target:        int(o.Target),
+		Consolidation: o.Consolidation.consolidationMode(),
+		TimeoutMs:     uint64(o.Timeout.Milliseconds()),
+		Payload:       o.Payload,
+		Encoding:      o.Encoding.toCGO(),
+		Attachment:    o.Attachment.toCGO(),
+	}
+}
+
 func Get(session *OwnedSession, selector string, handler ReplyCallback) error {
+	return GetWithOptions(session, selector, handler, nil)
+}
+
+// GetWithOptions sends a query on the given selector with custom options.
+// The selector parameters are forwarded to the queryables.
+// This is equivalent to z_get() in zenoh-c.
+func GetWithOptions(session *OwnedSession, selector string, handler ReplyCallback, opts *GetOptions) error {
+	var done func()
+	if opts != nil {
+		done = opts.OnDone
+	}
+	return getWithOptions(session, selector, handler, done, opts, nil)
+}
+
+// getWithOptions sends a query. done, if any, is called exactly once, either
+// when the query finishes or before returning an error.
+func getWithOptions(session *OwnedSession, selector string, handler ReplyCallback, done func(), opts *GetOptions, token *cgo.CancellationToken) error {
 	if session == nil || !session.IsValid() {
-		return ErrInvalidValue
+		return queryNotSent(done, ErrInvalidValue)
 	}
 	if selector == "" {
-		return ErrInvalidSelector
+		return queryNotSent(done, ErrInvalidSelector)
 	}
 	if handler == nil {
-		return errors.New("handler cannot be nil")
+		return queryNotSent(done, errors.New("handler cannot be nil"))
 	}
 
-	keyExpr, _, err := parseSelector(selector)
+	keyExpr, params, err := parseSelector(selector)
 	if err != nil {
-		return err
+		return queryNotSent(done, err)
+	}
+	return get(session, cgo.KeyExprArg{Expr: keyExpr}, params, handler, done, opts, token)
+}
+
+// GetKeyExpr is like GetWithOptions, but queries a key expression declared
+// with OwnedSession.DeclareKeyExpr, with the given selector parameters.
+// This is equivalent to z_get() in zenoh-c.
+func GetKeyExpr(session *OwnedSession, keyExpr *OwnedKeyExpr, parameters string, handler ReplyCallback, opts *GetOptions) error {
+	var done func()
+	if opts != nil {
+		done = opts.OnDone
+	}
+	if session == nil || !session.IsValid() || !keyExpr.IsValid() {
+		return queryNotSent(done, ErrInvalidValue)
+	}
+	if handler == nil {
+		return queryNotSent(done, errors.New("handler cannot be nil"))
+	}
+	return get(session, keyExpr.toCGO(), parameters, handler, done, opts, nil)
+}
+
+func get(session *OwnedSession, keyExpr cgo.KeyExprArg, params string, handler ReplyCallback, done func(), opts *GetOptions, token *cgo.CancellationToken) error {
+	cgoOpts := opts.toCGO()
+	if token != nil {
+		if cgoOpts == nil {
+			cgoOpts = DefaultGetOptions().toCGO()
+		}
+		cgoOpts.CancellationToken = token
 	}
 
 	s := cgo.SessionFromOwnedPtr(session.ptr, session.owned)
 	cb := func(data cgo.QueryReplyData) {
-		reply := Reply{
-			keyExpr: data.KeyExpr,
-			value:   data.Payload,
-			isOk:    data.Ok,
-			errMsg:  data.ErrMsg,
-			ptr:     0,
+		handler(replyFromCGO(data))
+	}
+	return s.GetWithOptions(keyExpr, params, cb, cgo.QueryDoneCallback(done), cgoOpts)
+}
+
+// queryNotSent calls done, if any, for a query that could not be sent and
+// returns err.
+func queryNotSent(done func(), err error) error {
+	if done != nil {
+		done()
+	}
+	return err
+}
+
+// GetContext sends a query and collects its replies until the query finishes.
+// If ctx is done first, the query is cancelled and ctx.Err() is returned.
+// A ctx deadline also bounds the query timeout on the zenoh side; a query
+// ended by that timeout returns ctx.Err() as well.
+func GetContext(ctx context.Context, session *OwnedSession, selector string, opts *GetOptions) ([]Reply, error) {
+	ch, err := GetWithChannelContext(ctx, session, selector, opts)
+	if err != nil {
+		return nil, err
+	}
+	var replies []Reply
+	for {
+		select {
+		case reply, ok := <-ch:
+			if !ok {
+				// The zenoh timeout and ctx.Done() may fire together.
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				return replies, nil
+			}
+			replies = append(replies, reply)
+		case <-ctx.Done():
+			return nil, ctx.Err()
 		}
-		if data.Ok {
-			reply.encoding = EncodingApplicationOctetStream
+	}
+}
+
+// GetWithChannelContext sends a query and streams its replies on the returned
+// channel, which is closed once the query finishes. Unlike GetWithChannel no
+// reply is dropped: delivery waits for the reader. If ctx is done first, the
+// query is cancelled, no further replies are delivered and the channel is closed.
+func GetWithChannelContext(ctx context.Context, session *OwnedSession, selector string, opts *GetOptions) (<-chan Reply, error) {
+	var onDone func()
+	if opts != nil {
+		onDone = opts.OnDone
+	}
+	if session == nil || !session.IsValid() {
+		return nil, queryNotSent(onDone, ErrInvalidValue)
+	}
+	if selector == "" {
+		return nil, queryNotSent(onDone, ErrInvalidSelector)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, queryNotSent(onDone, err)
+	}
+
+	opts = withContextDeadline(ctx, opts)
+	token, err := cgo.NewCancellationToken()
+	if err != nil {
+		return nil, queryNotSent(onDone, err)
+	}
+
+	out := make(chan Reply, 16)
+	finished := make(chan struct{})
+	handler := func(reply Reply) {
+		select {
+		case out <- reply:
+		case <-ctx.Done():
+		}
+	}
+	done := func() {
+		close(out)
+		close(finished)
+		if onDone != nil {
+			onDone()
+		}
+	}
+	if err := getWithOptions(session, selector, handler, done, opts, token); err != nil {
+		token.Drop()
+		return nil, err
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			token.Cancel()
+		case <-finished:
 		}
-		handler(reply)
+		token.Drop()
+	}()
+	return out, nil
+}
+
+// withContextDeadline returns opts with its timeout shortened to the ctx deadline, if any.
+// The time left is rounded up to the millisecond so that zenoh does not end the
+// query before the deadline. The caller's options are never modified.
+func withContextDeadline(ctx context.Context, opts *GetOptions) *GetOptions {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return opts
+	}
+	var o GetOptions
+	if opts != nil {
+		o = *opts
+	} else {
+		o = *DefaultGetOptions()
+	}
+	remaining := time.Until(deadline).Truncate(time.Millisecond) + time.Millisecond
+	if remaining < time.Millisecond {
+		remaining = time.Millisecond
 	}
-	return s.Get(keyExpr, cb)
+	if o.Timeout == 0 || remaining < o.Timeout {
+		o.Timeout = remaining
+	}
+	return &o
 }
 
 func GetWithChannel(session *OwnedSession, selector string) (*ReplyChannel, error) {
@@ -212,7 +429,7 @@ func GetWithChannel(session *OwnedSession, selector string) (*ReplyChannel, erro
 		return nil, ErrInvalidSelector
 	}
 
-	keyExpr, _, err := parseSelector(selector)
+	keyExpr, params, err := parseSelector(selector)
 	if err != nil {
 		return nil, err
 	}
@@ -220,21 +437,9 @@ func GetWithChannel(session *OwnedSession, selector string) (*ReplyChannel, erro
 	ch := NewReplyChannel(16)
 	s := cgo.SessionFromOwnedPtr(session.ptr, session.owned)
 	cb := func(data cgo.QueryReplyData) {
-		reply := Reply{
-			keyExpr: data.KeyExpr,
-			value:   data.Payload,
-			isOk:    data.Ok,
-			errMsg:  data.ErrMsg,
-			ptr:     0,
-		}
-		if data.Ok {
-			reply.encoding = EncodingApplicationOctetStream
-		}
-		ch.Send(reply)
+		ch.Send(replyFromCGO(data))
 	}
-	err = s.Get(keyExpr, cb)
-	if err != nil {
-		ch.Close()
+	if err := s.GetWithOptions(cgo.KeyExprArg{Expr: keyExpr}, params, cb, ch.Close, nil); err != nil {
 		return nil, err
 	}
 	return ch, nil
@@ -1,7 +1,13 @@
 package zenoh
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/wind-c/zenoh-go/internal/cgo"
 )
 
 func TestGet_Validation(t *testing.T) {
@@ -29,6 +35,168 @@ func TestGet_Validation(t *testing.T) {
 	}
 }
 
+func TestGetWithOptions_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		session  *OwnedSession
+		selector string
+		handler  ReplyCallback
+		opts     *GetOptions
+		wantErr  bool
+	}{
+		{"nil session", nil, "demo/test/*?a=1", func(r Reply) {}, DefaultGetOptions(), true},
+		{"invalid session", &OwnedSession{ptr: 0}, "demo/test/*", func(r Reply) {}, nil, true},
+		{"empty selector", &OwnedSession{ptr: 0}, "", func(r Reply) {}, nil, true},
+		{"nil handler", &OwnedSession{ptr: 0}, "demo/test/*", nil, nil, true},
+		{"selector without key expression", &OwnedSession{ptr: 1}, "?a=1", func(r Reply) {}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := GetWithOptions(tt.session, tt.selector, tt.handler, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			opts := DefaultGetOptions()
+			if tt.opts != nil {
+				*opts = *tt.opts
+			}
+			calls := 0
+			opts.OnDone = func() { calls++ }
+			if err := GetWithOptions(tt.session, tt.selector, tt.handler, opts); err != nil && calls != 1 {
+				t.Errorf("OnDone called %d times on error, want 1", calls)
+			}
+		})
+	}
+}
+
+func TestGetOptions_toCGO(t *testing.T) {
+	var nilOpts *GetOptions
+	if nilOpts.toCGO() != nil {
+		t.Error("nil GetOptions.toCGO() should return nil")
+	}
+
+	opts := &GetOptions{
+		Target:        QueryTargetAll,
+		Consolidation: ConsolidationNone,
+		Timeout:       1500 * time.Millisecond,
+		Payload:       []byte("body"),
+		Encoding:      EncodingApplicationJson,
+	}
+	c := opts.toCGO()
+	if c.Target != int(QueryTargetAll) {
+		t.Errorf("Target = %v, want %v", c.Target, int(QueryTargetAll))
+	}
+	if c.Consolidation != 0 {
+		t.Errorf("Consolidation = %v, want 0 (Z_CONSOLIDATION_MODE_NONE)", c.Consolidation)
+	}
+	if c.TimeoutMs != 1500 {
+		t.Errorf("TimeoutMs = %v, want 1500", c.TimeoutMs)
+	}
+	if string(c.Payload) != "body" {
+		t.Errorf("Payload = %q, want %q", c.Payload, "body")
+	}
+	if c.Encoding == nil || c.Encoding.Value != "application/json" {
+		t.Errorf("Encoding = %v, want application/json", c.Encoding)
+	}
+}
+
+func TestConsolidation_consolidationMode(t *testing.T) {
+	tests := []struct {
+		consolidation Consolidation
+		expected      int
+	}{
+		{ConsolidationAuto, -1},
+		{ConsolidationNone, 0},
+		{ConsolidationMonotonic, 1},
+		{ConsolidationLatest, 2},
+	}
+
+	for _, tt := range tests {
+		if got := tt.consolidation.consolidationMode(); got != tt.expected {
+			t.Errorf("Consolidation(%d).consolidationMode() = %v, want %v", tt.consolidation, got, tt.expected)
+		}
+	}
+}
+
+func TestGetContext_Validation(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		session  *OwnedSession
+		selector string
+		wantErr  error
+	}{
+		{"nil session", context.Background(), nil, "demo/test/*", ErrInvalidValue},
+		{"invalid session", context.Background(), &OwnedSession{ptr: 0}, "demo/test/*", ErrInvalidValue},
+		{"empty selector", context.Background(), &OwnedSession{ptr: 1}, "", ErrInvalidSelector},
+		{"cancelled context", cancelled, &OwnedSession{ptr: 1}, "demo/test/*", context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetContext(tt.ctx, tt.session, tt.selector, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetContext() error = %v, want %v", err, tt.wantErr)
+			}
+			_, err = GetWithChannelContext(tt.ctx, tt.session, tt.selector, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetWithChannelContext() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithContextDeadline(t *testing.T) {
+	t.Run("no deadline", func(t *testing.T) {
+		opts := &GetOptions{Timeout: time.Second}
+		if got := withContextDeadline(context.Background(), opts); got != opts {
+			t.Error("options should be returned unchanged without a deadline")
+		}
+	})
+
+	t.Run("deadline shorter than timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		opts := &GetOptions{Target: QueryTargetAll, Timeout: 10 * time.Second}
+		got := withContextDeadline(ctx, opts)
+		if got == opts {
+			t.Fatal("caller options should not be modified")
+		}
+		if got.Timeout <= 0 || got.Timeout > 101*time.Millisecond || got.Timeout%time.Millisecond != 0 {
+			t.Errorf("Timeout = %v, want whole milliseconds up to 101ms", got.Timeout)
+		}
+		if got.Target != QueryTargetAll {
+			t.Errorf("Target = %v, want %v", got.Target, QueryTargetAll)
+		}
+		if opts.Timeout != 10*time.Second {
+			t.Error("caller options should not be modified")
+		}
+	})
+
+	t.Run("timeout shorter than deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		got := withContextDeadline(ctx, &GetOptions{Timeout: time.Second})
+		if got.Timeout != time.Second {
+			t.Errorf("Timeout = %v, want 1s", got.Timeout)
+		}
+	})
+
+	t.Run("nil options", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		got := withContextDeadline(ctx, nil)
+		if got == nil || got.Timeout <= 0 || got.Timeout > time.Minute {
+			t.Errorf("withContextDeadline(nil) = %+v, want timeout bounded by deadline", got)
+		}
+	})
+}
+
 func TestGetWithChannel_Validation(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -271,4 +439,82 @@ func TestReplyIterator(t *testing.T) {
 			t.Errorf("Expected error 'not found', got '%s'", reply.Error())
 		}
 	})
+	t.Run("finished after close", func(t *testing.T) {
+		ch := NewReplyChannel(4)
+		ch.Send(Reply{keyExpr: "key1", value: []byte("val1"), isOk: true})
+
+		it := NewReplyIterator(ch.Chan())
+		go ch.Close()
+
+		count := 0
+		for it.Next() {
+			count++
+		}
+		if count != 1 {
+			t.Errorf("Expected 1 reply, got %d", count)
+		}
+		if it.Valid() {
+			t.Error("Valid() should return false once the iterator is finished")
+		}
+		if it.Next() {
+			t.Error("Next() should keep returning false once the iterator is finished")
+		}
+	})
+}
+
+func TestReplyFromCGO_Error(t *testing.T) {
+	reply := replyFromCGO(cgo.QueryReplyData{Payload: []byte(`{"code":404}`), Encoding: "application/json", ErrMsg: `{"code":404}`})
+	if reply.IsOk() || reply.Error() != `{"code":404}` {
+		t.Errorf("reply = %v, want error reply", reply.String())
+	}
+	if reply.Encoding() == nil || reply.Encoding().String() != "application/json" {
+		t.Errorf("Encoding() = %v, want application/json", reply.Encoding())
+	}
+
+	reply = replyFromCGO(cgo.QueryReplyData{Ok: true, Kind: int(SampleKindDelete)})
+	if reply.Kind() != SampleKindDelete {
+		t.Errorf("Kind() = %s, want %s", reply.Kind(), SampleKindDelete)
+	}
+}
+
+func TestGetContext_DeadlineExceeded_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	session := openLoopbackPeer(t, "tcp/127.0.0.1:17462")
+	defer session.Drop()
+
+	// The queryable keeps every query open without replying.
+	var mu sync.Mutex
+	var held []*Query
+	queryable, err := DeclareQueryable(session, "demo/silent", func(q Query) {
+		clone, err := q.Clone()
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		held = append(held, clone)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("DeclareQueryable() error = %v", err)
+	}
+	defer queryable.Drop()
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, q := range held {
+			q.Drop()
+		}
+	}()
+
+	for range 5 {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		replies, err := GetContext(ctx, session, "demo/silent", nil)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("GetContext() = %v, %v, want context.DeadlineExceeded", replies, err)
+		}
+	}
 }
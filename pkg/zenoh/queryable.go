@@ -2,6 +2,7 @@ package zenoh
 
 import (
 	"errors"
+	"sync"
 
 	"github.com/wind-c/zenoh-go/internal/cgo"
 )
@@ -13,6 +14,8 @@ type Query struct {
 	keyExpr    string
 	parameters string
 	payload    []byte
+	encoding   *Encoding
+	attachment *Attachment
 	ptr        uintptr
 	cgoQuery   *cgo.Query
 }
@@ -24,6 +27,8 @@ func (q *Query) KeyExpr() string {
 	return q.keyExpr
 }
 
+// Parameters returns the selector parameters of the query as sent, without
+// the '?'. Use Selector to read them as typed Parameters.
 func (q *Query) Parameters() string {
 	if q == nil {
 		return ""
@@ -31,6 +36,14 @@ func (q *Query) Parameters() string {
 	return q.parameters
 }
 
+// Selector returns the key expression and the parameters of the query.
+func (q *Query) Selector() Selector {
+	if q == nil {
+		return Selector{}
+	}
+	return Selector{KeyExpr: q.keyExpr, Parameters: ParseParameters(q.parameters)}
+}
+
 func (q *Query) Value() []byte {
 	if q == nil {
 		return nil
@@ -38,27 +51,191 @@ func (q *Query) Value() []byte {
 	return q.payload
 }
 
+// Payload returns the payload sent with the query, or nil if it carries none.
+// This is equivalent to z_query_payload() in zenoh-c.
+func (q *Query) Payload() []byte {
+	return q.Value()
+}
+
+// Encoding returns the encoding of the query payload, or nil if the query
+// carries none.
+// This is equivalent to z_query_encoding() in zenoh-c.
+func (q *Query) Encoding() *Encoding {
+	if q == nil {
+		return nil
+	}
+	return q.encoding
+}
+
+// Attachment returns the attachment sent with the query, or nil if it carries none.
+func (q *Query) Attachment() *Attachment {
+	if q == nil {
+		return nil
+	}
+	return q.attachment
+}
+
 func (q *Query) Reply(keyExpr string, payload []byte, encoding *Encoding) error {
 	if q == nil || (q.ptr == 0 && q.cgoQuery == nil) {
 		return ErrInvalidQuery
 	}
 	if q.cgoQuery != nil {
-		return q.cgoQuery.Reply(keyExpr, payload)
+		return q.cgoQuery.Reply(keyExpr, payload, encoding.toCGO())
 	}
 	return errors.New("Query.Reply requires cgo query")
 }
 
-func (q *Query) ReplyErr(payload []byte) error {
+// ReplyOptions contains options for replying to a query.
+type ReplyOptions struct {
+	// Encoding specifies the encoding of the payload.
+	Encoding *Encoding
+	// CongestionControl specifies the congestion control mode.
+	CongestionControl CongestionControl
+	// Priority specifies the reply priority. Zero selects PriorityDefault.
+	Priority Priority
+	// IsExpress disables batching for this reply, trading throughput for latency.
+	IsExpress bool
+	// Attachment is optional user metadata sent alongside the payload.
+	Attachment *Attachment
+	// Timestamp, if set, is sent instead of a timestamp from the session clock.
+	Timestamp *ZenohTimestamp
+}
+
+// DefaultReplyOptions returns default reply options.
+func DefaultReplyOptions() *ReplyOptions {
+	return &ReplyOptions{
+		CongestionControl: CongestionControlBlock,
+		Priority:          PriorityDefault,
+	}
+}
+
+// ReplyWithOptions replies to the query with custom options.
+// This is equivalent to z_query_reply() in zenoh-c.
+func (q *Query) ReplyWithOptions(keyExpr string, payload []byte, opts *ReplyOptions) error {
+	if q == nil || q.cgoQuery == nil {
+		return ErrInvalidQuery
+	}
+	if opts == nil {
+		opts = DefaultReplyOptions()
+	}
+	return q.cgoQuery.ReplyWithOptions(keyExpr, payload, &cgo.ReplyOptions{
+		Encoding:          opts.Encoding.toCGO(),
+		CongestionControl: int(opts.CongestionControl),
+		Priority:          int(opts.Priority),
+		IsExpress:         opts.IsExpress,
+		Attachment:        opts.Attachment.toCGO(),
+		Timestamp:         opts.Timestamp.toCGO(),
+	})
+}
+
+// ReplyDeleteOptions contains options for DELETE replies.
+type ReplyDeleteOptions struct {
+	// CongestionControl specifies the congestion control mode.
+	CongestionControl CongestionControl
+	// Priority specifies the reply priority. Zero selects PriorityDefault.
+	Priority Priority
+	// IsExpress disables batching for this reply, trading throughput for latency.
+	IsExpress bool
+	// Attachment is optional user metadata sent alongside the reply.
+	Attachment *Attachment
+	// Timestamp, if set, is sent instead of a timestamp from the session clock.
+	Timestamp *ZenohTimestamp
+}
+
+// DefaultReplyDeleteOptions returns default DELETE reply options.
+func DefaultReplyDeleteOptions() *ReplyDeleteOptions {
+	return &ReplyDeleteOptions{
+		CongestionControl: CongestionControlBlock,
+		Priority:          PriorityDefault,
+	}
+}
+
+// ReplyDelete replies to the query with a DELETE on the given key expression,
+// for instance to report that a stored value no longer exists.
+// The querier receives it as a sample of kind SampleKindDelete.
+// This is equivalent to z_query_reply_del() in zenoh-c.
+func (q *Query) ReplyDelete(keyExpr string, opts *ReplyDeleteOptions) error {
+	if q == nil || q.cgoQuery == nil {
+		return ErrInvalidQuery
+	}
+	if keyExpr == "" {
+		return ErrInvalidKeyExpr
+	}
+	if opts == nil {
+		opts = DefaultReplyDeleteOptions()
+	}
+	return q.cgoQuery.ReplyDel(keyExpr, &cgo.ReplyDelOptions{
+		CongestionControl: int(opts.CongestionControl),
+		Priority:          int(opts.Priority),
+		IsExpress:         opts.IsExpress,
+		Attachment:        opts.Attachment.toCGO(),
+		Timestamp:         opts.Timestamp.toCGO(),
+	})
+}
+
+// ReplyErr replies to the query with an error. The encoding, which may be
+// nil, describes the payload so that structured error bodies can be sent.
+// This is equivalent to z_query_reply_err() in zenoh-c.
+func (q *Query) ReplyErr(payload []byte, encoding *Encoding) error {
 	if q == nil || (q.ptr == 0 && q.cgoQuery == nil) {
 		return ErrInvalidQuery
 	}
 	if q.cgoQuery != nil {
-		errMsg := string(payload)
-		return q.cgoQuery.ReplyErr(errMsg)
+		return q.cgoQuery.ReplyErrWithEncoding(payload, encoding.toCGO())
 	}
 	return errors.New("Query.ReplyErr requires cgo query")
 }
 
+// Clone returns an owned copy of the query. Unlike a query passed to a
+// QueryCallback, which is only valid until the callback returns, the clone
+// can be replied to later from any goroutine, for instance after a slow
+// database lookup.
+//
+// The clone must be dropped after its last reply: dropping it tells the
+// querier that no more replies will follow.
+//
+// Example:
+//
+//	zenoh.DeclareQueryable(session, "db/**", func(q zenoh.Query) {
+//	    owned, err := q.Clone()
+//	    if err != nil {
+//	        return
+//	    }
+//	    go func() {
+//	        defer owned.Drop()
+//	        owned.Reply(owned.KeyExpr(), lookup(owned.KeyExpr()), nil)
+//	    }()
+//	})
+//
+// This is equivalent to z_query_clone() in zenoh-c.
+func (q *Query) Clone() (*Query, error) {
+	if q == nil || q.cgoQuery == nil {
+		return nil, ErrInvalidQuery
+	}
+	owned, err := q.cgoQuery.Clone()
+	if err != nil {
+		return nil, ErrInvalidQuery
+	}
+	clone := *q
+	clone.cgoQuery = owned
+	return &clone, nil
+}
+
+// Drop releases an owned query, obtained from Clone or received through
+// DeclareQueryableWithChannel, which tells the querier that no more replies
+// will follow. Owned queries must be dropped once answered, or the querier
+// waits until its timeout. Queries passed to a QueryCallback are released
+// when the callback returns, so Drop is a no-op on them.
+//
+// It is safe to call Drop multiple times; subsequent calls are no-ops.
+// This is equivalent to z_query_drop() in zenoh-c.
+func (q *Query) Drop() {
+	if q == nil || q.cgoQuery == nil {
+		return
+	}
+	q.cgoQuery.Drop()
+}
+
 type QueryCallback func(query Query)
 
 type queryableClosure struct {
@@ -66,7 +243,11 @@ type queryableClosure struct {
 	channel  *QueryChannel
 }
 
+// QueryChannel buffers the queries of a queryable declared with
+// DeclareQueryableWithChannel. It is closed once the queryable is dropped,
+// so ranging over Chan() terminates.
 type QueryChannel struct {
+	mu     sync.Mutex
 	ch     chan Query
 	closed bool
 }
@@ -84,7 +265,11 @@ func (r *QueryChannel) Chan() <-chan Query {
 	return r.ch
 }
 
+// Send delivers the query without blocking. When the channel is full, the
+// oldest query is dropped unanswered to make room.
 func (r *QueryChannel) Send(query Query) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if r.closed {
 		return false
 	}
@@ -93,7 +278,8 @@ func (r *QueryChannel) Send(query Query) bool {
 		return true
 	default:
 		select {
-		case <-r.ch:
+		case old := <-r.ch:
+			old.Drop()
 			r.ch <- query
 			return true
 		default:
@@ -103,6 +289,8 @@ func (r *QueryChannel) Send(query Query) bool {
 }
 
 func (r *QueryChannel) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if !r.closed {
 		r.closed = true
 		close(r.ch)
@@ -110,6 +298,8 @@ func (r *QueryChannel) Close() {
 }
 
 func (r *QueryChannel) IsClosed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.closed
 }
 
@@ -121,21 +311,52 @@ func (q *OwnedQueryable) Undeclare() error {
 	if q == nil || q.ptr == 0 {
 		return nil
 	}
-	qable := cgo.QueryableFromPtr(q.ptr)
-	err := qable.Undeclare()
-	if err != nil {
-		return err
-	}
+	qable := cgo.QueryableFromOwnedPtr(q.ptr, q.owned, q.handle)
 	q.ptr = 0
+	q.owned = nil
 	q.handle = 0
-	return nil
+	return qable.Undeclare()
 }
 
 func (q *OwnedQueryable) Drop() error {
 	return q.Undeclare()
 }
 
+// QueryableOptions contains options for Queryable declaration.
+type QueryableOptions struct {
+	// Complete declares that the queryable holds every value matching its
+	// key expression, so queries with QueryTargetComplete can be routed to it.
+	Complete bool
+	// AllowedOrigin restricts the sessions whose queries the queryable receives.
+	AllowedOrigin Locality
+}
+
+// DefaultQueryableOptions returns default queryable options.
+func DefaultQueryableOptions() *QueryableOptions {
+	return &QueryableOptions{
+		AllowedOrigin: LocalityAny,
+	}
+}
+
+func queryFromCGO(cgoQuery *cgo.Query) Query {
+	return Query{
+		keyExpr:    cgoQuery.KeyExpr,
+		parameters: cgoQuery.Parameters,
+		payload:    cgoQuery.Payload,
+		encoding:   EncodingFromStr(cgoQuery.Encoding),
+		attachment: attachmentFromCGO(cgoQuery.Attachment),
+		cgoQuery:   cgoQuery,
+	}
+}
+
 func DeclareQueryable(session *OwnedSession, keyExpr string, callback QueryCallback) (*OwnedQueryable, error) {
+	return DeclareQueryableWithOptions(session, keyExpr, callback, nil)
+}
+
+// DeclareQueryableWithOptions declares a queryable with custom options.
+// The callback runs on a zenoh thread and the query is only valid until it returns.
+// This is equivalent to z_declare_queryable() in zenoh-c.
+func DeclareQueryableWithOptions(session *OwnedSession, keyExpr string, callback QueryCallback, opts *QueryableOptions) (*OwnedQueryable, error) {
 	if session == nil || !session.IsValid() {
 		return nil, ErrInvalidValue
 	}
@@ -145,20 +366,91 @@ func DeclareQueryable(session *OwnedSession, keyExpr string, callback QueryCallb
 	if callback == nil {
 		return nil, errors.New("callback cannot be nil")
 	}
+	return declareQueryable(session, cgo.KeyExprArg{Expr: keyExpr}, queryableCallback(callback), nil, opts)
+}
 
-	s := cgo.SessionFromOwnedPtr(session.ptr, session.owned)
+// DeclareQueryableKeyExpr is like DeclareQueryableWithOptions, but on a key
+// expression declared with OwnedSession.DeclareKeyExpr.
+// This is equivalent to z_declare_queryable() in zenoh-c.
+func DeclareQueryableKeyExpr(session *OwnedSession, keyExpr *OwnedKeyExpr, callback QueryCallback, opts *QueryableOptions) (*OwnedQueryable, error) {
+	if session == nil || !session.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	if !keyExpr.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	if callback == nil {
+		return nil, errors.New("callback cannot be nil")
+	}
+	return declareQueryable(session, keyExpr.toCGO(), queryableCallback(callback), nil, opts)
+}
+
+func queryableCallback(callback QueryCallback) cgo.QueryableCallback {
+	return func(cgoQuery cgo.Query) {
+		callback(queryFromCGO(&cgoQuery))
+		// zenoh-c releases the loaned query once the callback returns, so
+		// replying through a retained copy must fail instead of using it.
+		cgoQuery = cgo.Query{}
+	}
+}
+
+// DeclareQueryableWithChannel declares a queryable that delivers its queries
+// on a channel, so they can be answered off the zenoh callback thread, for
+// instance by a pool of workers. The channel is closed once the queryable
+// is dropped. Each received query must be dropped after its last reply.
+//
+// Example:
+//
+//	queryable, queries, err := zenoh.DeclareQueryableWithChannel(session, "db/**", 64, nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer queryable.Drop()
+//	for i := 0; i < workers; i++ {
+//	    go func() {
+//	        for q := range queries.Chan() {
+//	            q.Reply(q.KeyExpr(), lookup(q.KeyExpr()), nil)
+//	            q.Drop()
+//	        }
+//	    }()
+//	}
+func DeclareQueryableWithChannel(session *OwnedSession, keyExpr string, bufferSize int, opts *QueryableOptions) (*OwnedQueryable, *QueryChannel, error) {
+	if session == nil || !session.IsValid() {
+		return nil, nil, ErrInvalidValue
+	}
+	if keyExpr == "" {
+		return nil, nil, ErrInvalidKeyExpr
+	}
+
+	channel := NewQueryChannel(bufferSize)
 	cgoCallback := func(cgoQuery cgo.Query) {
-		query := Query{
-			keyExpr:    cgoQuery.KeyExpr,
-			parameters: cgoQuery.Parameters,
-			payload:    cgoQuery.Payload,
-			cgoQuery:   &cgoQuery,
+		owned, err := cgoQuery.Clone()
+		if err != nil {
+			return
 		}
-		callback(query)
+		query := queryFromCGO(owned)
+		if !channel.Send(query) {
+			query.Drop()
+		}
+	}
+	qable, err := declareQueryable(session, cgo.KeyExprArg{Expr: keyExpr}, cgoCallback, channel.Close, opts)
+	if err != nil {
+		return nil, nil, err
 	}
-	qable, err := s.DeclareQueryable(keyExpr, cgoCallback)
+	return qable, channel, nil
+}
+
+func declareQueryable(session *OwnedSession, keyExpr cgo.KeyExprArg, callback cgo.QueryableCallback, done func(), opts *QueryableOptions) (*OwnedQueryable, error) {
+	if opts == nil {
+		opts = DefaultQueryableOptions()
+	}
+	s := cgo.SessionFromOwnedPtr(session.ptr, session.owned)
+	qable, err := s.DeclareQueryableWithOptions(keyExpr, callback, cgo.QueryableDoneCallback(done), &cgo.QueryableOptions{
+		Complete:      opts.Complete,
+		AllowedOrigin: int(opts.AllowedOrigin),
+	})
 	if err != nil {
 		return nil, err
 	}
-	return &OwnedQueryable{ptr: qable.Ptr, handle: qable.Handle()}, nil
+	return &OwnedQueryable{ptr: qable.Ptr, owned: qable.OwnedPtr(), handle: qable.Handle()}, nil
 }
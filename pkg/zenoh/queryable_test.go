@@ -1,7 +1,14 @@
 package zenoh
 
 import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/wind-c/zenoh-go/internal/cgo"
 )
 
 func TestQueryChannel(t *testing.T) {
@@ -101,3 +108,331 @@ func TestQueryableNil(t *testing.T) {
 		t.Error("nil Queryable should not be valid")
 	}
 }
+
+func TestQueryChannel_FullKeepsLatest(t *testing.T) {
+	ch := NewQueryChannel(1)
+	ch.Send(Query{keyExpr: "first"})
+	if !ch.Send(Query{keyExpr: "second"}) {
+		t.Fatal("Send on a full channel should replace the oldest query")
+	}
+	if q := <-ch.Chan(); q.keyExpr != "second" {
+		t.Errorf("expected the latest query, got %q", q.keyExpr)
+	}
+}
+
+func TestQueryDrop_NotOwned(t *testing.T) {
+	var nilQuery *Query
+	nilQuery.Drop()
+	(&Query{keyExpr: "test"}).Drop()
+}
+
+func TestQueryableOptions_Default(t *testing.T) {
+	opts := DefaultQueryableOptions()
+	if opts.Complete {
+		t.Error("Expected Complete=false")
+	}
+	if opts.AllowedOrigin != LocalityAny {
+		t.Errorf("Expected AllowedOrigin=LocalityAny, got %v", opts.AllowedOrigin)
+	}
+}
+
+func TestDeclareQueryableWithOptions_Validation(t *testing.T) {
+	cb := func(Query) {}
+
+	if _, err := DeclareQueryableWithOptions(nil, "demo/test", cb, nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("DeclareQueryableWithOptions() on nil session error = %v, want ErrInvalidValue", err)
+	}
+	if _, err := DeclareQueryableWithOptions(&OwnedSession{ptr: 1}, "", cb, nil); !errors.Is(err, ErrInvalidKeyExpr) {
+		t.Errorf("DeclareQueryableWithOptions() with empty keyExpr error = %v, want ErrInvalidKeyExpr", err)
+	}
+	if _, err := DeclareQueryableWithOptions(&OwnedSession{ptr: 1}, "demo/test", nil, nil); err == nil {
+		t.Error("DeclareQueryableWithOptions() with nil callback should return error")
+	}
+	if _, _, err := DeclareQueryableWithChannel(&OwnedSession{}, "demo/test", 1, nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("DeclareQueryableWithChannel() on invalid session error = %v, want ErrInvalidValue", err)
+	}
+	if _, _, err := DeclareQueryableWithChannel(&OwnedSession{ptr: 1}, "", 1, nil); !errors.Is(err, ErrInvalidKeyExpr) {
+		t.Errorf("DeclareQueryableWithChannel() with empty keyExpr error = %v, want ErrInvalidKeyExpr", err)
+	}
+}
+
+func TestDeclareQueryableWithChannel_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	session := openLoopbackPeer(t, "tcp/127.0.0.1:17454")
+	defer session.Drop()
+
+	opts := DefaultQueryableOptions()
+	opts.Complete = true
+	queryable, queries, err := DeclareQueryableWithChannel(session, "demo/workers/*", 8, opts)
+	if err != nil {
+		t.Fatalf("DeclareQueryableWithChannel() error = %v", err)
+	}
+
+	var workers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for q := range queries.Chan() {
+				time.Sleep(10 * time.Millisecond)
+				q.Reply(q.KeyExpr(), []byte("done"), nil)
+				q.Drop()
+			}
+		}()
+	}
+
+	getOpts := DefaultGetOptions()
+	getOpts.Target = QueryTargetComplete
+	getOpts.Timeout = 5 * time.Second
+	replies, err := GetContext(t.Context(), session, "demo/workers/a", getOpts)
+	if err != nil {
+		t.Fatalf("GetContext() error = %v", err)
+	}
+	if len(replies) != 1 || string(replies[0].Value()) != "done" {
+		t.Errorf("replies = %v, want one reply with value done", replies)
+	}
+
+	if err := queryable.Drop(); err != nil {
+		t.Fatalf("Drop() error = %v", err)
+	}
+	finished := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("query channel not closed after Drop()")
+	}
+}
+
+func TestQueryClone_Invalid(t *testing.T) {
+	var nilQuery *Query
+	if _, err := nilQuery.Clone(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Clone() on nil query error = %v, want ErrInvalidQuery", err)
+	}
+	if _, err := (&Query{keyExpr: "test"}).Clone(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Clone() without cgo query error = %v, want ErrInvalidQuery", err)
+	}
+
+	released := &Query{keyExpr: "test", cgoQuery: &cgo.Query{}}
+	if _, err := released.Clone(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Clone() on released query error = %v, want ErrInvalidQuery", err)
+	}
+	if err := released.Reply("test", []byte("late"), nil); err == nil {
+		t.Error("Reply() on released query should return error")
+	}
+	if err := released.ReplyErr([]byte("late"), nil); err == nil {
+		t.Error("ReplyErr() on released query should return error")
+	}
+}
+
+func TestQueryClone_DeferredReply_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	session := openLoopbackPeer(t, "tcp/127.0.0.1:17455")
+	defer session.Drop()
+
+	retained := make(chan Query, 1)
+	queryable, err := DeclareQueryable(session, "demo/deferred", func(q Query) {
+		retained <- q
+		owned, err := q.Clone()
+		if err != nil {
+			t.Errorf("Clone() error = %v", err)
+			return
+		}
+		go func() {
+			defer owned.Drop()
+			time.Sleep(100 * time.Millisecond)
+			owned.Reply(owned.KeyExpr(), []byte("slow"), nil)
+		}()
+	})
+	if err != nil {
+		t.Fatalf("DeclareQueryable() error = %v", err)
+	}
+	defer queryable.Drop()
+
+	opts := DefaultGetOptions()
+	opts.Timeout = 5 * time.Second
+	start := time.Now()
+	replies, err := GetContext(t.Context(), session, "demo/deferred", opts)
+	if err != nil {
+		t.Fatalf("GetContext() error = %v", err)
+	}
+	if len(replies) != 1 || string(replies[0].Value()) != "slow" {
+		t.Errorf("replies = %v, want one reply with value slow", replies)
+	}
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("query took %v, dropping the clone should finish it", elapsed)
+	}
+
+	q := <-retained
+	if err := q.Reply(q.KeyExpr(), []byte("late"), nil); err == nil {
+		t.Error("Reply() after the callback returned should return error")
+	}
+}
+
+func TestReplyOptions_Default(t *testing.T) {
+	opts := DefaultReplyOptions()
+	if opts.CongestionControl != CongestionControlBlock {
+		t.Errorf("Expected CongestionControl=CongestionControlBlock, got %v", opts.CongestionControl)
+	}
+	if opts.Priority != PriorityDefault {
+		t.Errorf("Expected Priority=PriorityDefault, got %v", opts.Priority)
+	}
+
+	delOpts := DefaultReplyDeleteOptions()
+	if delOpts.CongestionControl != CongestionControlBlock {
+		t.Errorf("Expected CongestionControl=CongestionControlBlock, got %v", delOpts.CongestionControl)
+	}
+	if delOpts.Priority != PriorityDefault {
+		t.Errorf("Expected Priority=PriorityDefault, got %v", delOpts.Priority)
+	}
+}
+
+func TestQueryReplyDelete_Validation(t *testing.T) {
+	var nilQuery *Query
+	if err := nilQuery.ReplyDelete("demo/test", nil); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("ReplyDelete() on nil query error = %v, want ErrInvalidQuery", err)
+	}
+	if err := (&Query{}).ReplyDelete("demo/test", nil); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("ReplyDelete() without cgo query error = %v, want ErrInvalidQuery", err)
+	}
+	if err := (&Query{cgoQuery: &cgo.Query{}}).ReplyDelete("", nil); !errors.Is(err, ErrInvalidKeyExpr) {
+		t.Errorf("ReplyDelete() with empty keyExpr error = %v, want ErrInvalidKeyExpr", err)
+	}
+	if err := (&Query{cgoQuery: &cgo.Query{}}).ReplyDelete("demo/test", nil); err == nil {
+		t.Error("ReplyDelete() on released query should return error")
+	}
+}
+
+func TestQueryReplies_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	session := openLoopbackPeer(t, "tcp/127.0.0.1:17456")
+	defer session.Drop()
+
+	queryable, err := DeclareQueryable(session, "demo/replies/*", func(q Query) {
+		switch q.KeyExpr() {
+		case "demo/replies/put":
+			opts := DefaultReplyOptions()
+			opts.Encoding = EncodingApplicationJson
+			opts.Priority = PriorityInteractiveHigh
+			opts.IsExpress = true
+			q.ReplyWithOptions(q.KeyExpr(), []byte(`{"v":1}`), opts)
+		case "demo/replies/delete":
+			q.ReplyDelete(q.KeyExpr(), nil)
+		default:
+			q.ReplyErr([]byte(`{"code":404}`), EncodingApplicationJson)
+		}
+	})
+	if err != nil {
+		t.Fatalf("DeclareQueryable() error = %v", err)
+	}
+	defer queryable.Drop()
+
+	get := func(keyExpr string) Reply {
+		t.Helper()
+		replies, err := GetContext(t.Context(), session, keyExpr, nil)
+		if err != nil {
+			t.Fatalf("GetContext(%q) error = %v", keyExpr, err)
+		}
+		if len(replies) != 1 {
+			t.Fatalf("GetContext(%q) returned %d replies, want 1", keyExpr, len(replies))
+		}
+		return replies[0]
+	}
+
+	if r := get("demo/replies/put"); !r.IsOk() || r.Kind() != SampleKindPut || r.Encoding().String() != EncodingApplicationJson.String() {
+		t.Errorf("put reply = %v kind %s encoding %v", r.String(), r.Kind(), r.Encoding())
+	}
+	if r := get("demo/replies/delete"); !r.IsOk() || r.Kind() != SampleKindDelete || r.KeyExpr() != "demo/replies/delete" {
+		t.Errorf("delete reply = %v kind %s", r.String(), r.Kind())
+	}
+	if r := get("demo/replies/missing"); r.IsOk() || r.Error() != `{"code":404}` || r.Encoding().String() != EncodingApplicationJson.String() {
+		t.Errorf("error reply = %v encoding %v", r.String(), r.Encoding())
+	}
+}
+
+func TestQueryFromCGO(t *testing.T) {
+	body := []byte(strings.Repeat(`{"k":"v"}`, 1000))
+	q := queryFromCGO(&cgo.Query{
+		KeyExpr:    "demo/q",
+		Parameters: "a=1;b=2",
+		Payload:    body,
+		Encoding:   "application/json",
+		Attachment: []byte("meta"),
+	})
+
+	if !bytes.Equal(q.Payload(), body) {
+		t.Errorf("Payload() length = %d, want %d", len(q.Payload()), len(body))
+	}
+	if q.Encoding() == nil || q.Encoding().String() != "application/json" {
+		t.Errorf("Encoding() = %v, want application/json", q.Encoding())
+	}
+	if string(q.Attachment().Bytes()) != "meta" {
+		t.Errorf("Attachment() = %q, want %q", q.Attachment().Bytes(), "meta")
+	}
+	if sel := q.Selector(); sel.KeyExpr != "demo/q" || sel.Parameters.String() != "a=1;b=2" || sel.String() != "demo/q?a=1;b=2" {
+		t.Errorf("Selector() = %+v", sel)
+	}
+
+	empty := queryFromCGO(&cgo.Query{KeyExpr: "demo/q"})
+	if empty.Payload() != nil || empty.Encoding() != nil || empty.Attachment() != nil {
+		t.Error("query without payload should have nil Payload(), Encoding() and Attachment()")
+	}
+	if got := empty.Selector().String(); got != "demo/q" {
+		t.Errorf("Selector().String() = %q, want %q", got, "demo/q")
+	}
+
+	var nilQuery *Query
+	if nilQuery.Payload() != nil || nilQuery.Encoding() != nil || nilQuery.Selector() != (Selector{}) {
+		t.Error("nil query should return zero values")
+	}
+}
+
+func TestQueryPayload_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	session := openLoopbackPeer(t, "tcp/127.0.0.1:17457")
+	defer session.Drop()
+
+	received := make(chan Query, 1)
+	queryable, err := DeclareQueryable(session, "demo/payload", func(q Query) {
+		received <- q
+		q.Reply(q.KeyExpr(), nil, nil)
+	})
+	if err != nil {
+		t.Fatalf("DeclareQueryable() error = %v", err)
+	}
+	defer queryable.Drop()
+
+	body := []byte(strings.Repeat("x", 64*1024))
+	opts := DefaultGetOptions()
+	opts.Payload = body
+	opts.Encoding = EncodingApplicationJson
+	if _, err := GetContext(t.Context(), session, "demo/payload?id=7", opts); err != nil {
+		t.Fatalf("GetContext() error = %v", err)
+	}
+
+	q := <-received
+	if len(q.Payload()) != len(body) {
+		t.Errorf("Payload() length = %d, want %d", len(q.Payload()), len(body))
+	}
+	if q.Encoding() == nil || q.Encoding().String() != EncodingApplicationJson.String() {
+		t.Errorf("Encoding() = %v, want %v", q.Encoding(), EncodingApplicationJson)
+	}
+	if got := q.Selector().String(); got != "demo/payload?id=7" {
+		t.Errorf("Selector() = %q, want %q", got, "demo/payload?id=7")
+	}
+}
@@ -1,38 +1,64 @@
 package zenoh
 
 import (
-	"errors"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/wind-c/zenoh-go/internal/cgo"
 )
 
 // =============================================================================
 // WhatAmI - Scout Target Types
 // =============================================================================
 
-// WhatAmI represents the type of zenoh entity to scout for.
+// WhatAmI represents the type of zenoh entity to scout for. The values are
+// bits that may be combined to scout for several kinds at once, as in
+// WhatAmIRouter|WhatAmIPeer.
 type WhatAmI uint8
 
 const (
 	// WhatAmIRouter scouts for routers.
-	WhatAmIRouter WhatAmI = iota
+	WhatAmIRouter WhatAmI = 1 << iota
 	// WhatAmIPeer scouts for peers.
 	WhatAmIPeer
 	// WhatAmIClient scouts for clients.
 	WhatAmIClient
+
+	whatAmIAll = WhatAmIRouter | WhatAmIPeer | WhatAmIClient
 )
 
-// String returns the string representation of WhatAmI.
+// String returns the string representation of WhatAmI. Combined kinds are
+// separated by '|', as in "router|peer".
 func (w WhatAmI) String() string {
-	switch w {
-	case WhatAmIRouter:
-		return "router"
-	case WhatAmIPeer:
-		return "peer"
-	case WhatAmIClient:
-		return "client"
-	default:
+	if w == 0 || w&^whatAmIAll != 0 {
 		return "unknown"
 	}
+	var kinds []string
+	for _, k := range []struct {
+		w    WhatAmI
+		name string
+	}{{WhatAmIRouter, "router"}, {WhatAmIPeer, "peer"}, {WhatAmIClient, "client"}} {
+		if w&k.w != 0 {
+			kinds = append(kinds, k.name)
+		}
+	}
+	return strings.Join(kinds, "|")
+}
+
+// what returns the z_what_t bitmask of the WhatAmI, or 0 if it holds no kind
+// or an unknown one.
+func (w WhatAmI) what() int {
+	if w&^whatAmIAll != 0 {
+		return 0
+	}
+	return int(w)
+}
+
+// whatAmIFromCGO converts a z_whatami_t value to a WhatAmI. The z_whatami_t
+// values are the same bits as the WhatAmI ones.
+func whatAmIFromCGO(v int) WhatAmI {
+	return WhatAmI(v)
 }
 
 // =============================================================================
@@ -42,16 +68,31 @@ func (w WhatAmI) String() string {
 // ScoutOptions contains options for the Scout operation.
 // This is equivalent to z_scout_options_t in zenoh-c.
 type ScoutOptions struct {
-	// WhatAmI defines the type of entities to scout for (router, peer, or client).
+	// WhatAmI adds kinds of entities to scout for to the ones passed to
+	// Scout or ScoutBlocking. Kinds may be combined, as in
+	// WhatAmIRouter|WhatAmIPeer.
 	WhatAmI WhatAmI
 
 	// Timeout is the duration to scout for.
-	// If 0, scouting runs indefinitely until cancelled.
+	// If 0, the zenoh-c default of one second is used.
 	Timeout time.Duration
 
-	// StartAsync enables asynchronous scouting (callback-based).
-	// If false, scouting returns a channel of Hello messages.
+	// StartAsync used to select between callback-based and channel-based
+	// scouting.
+	//
+	// Deprecated: Scout always scouts in the background and ScoutBlocking
+	// never does. StartAsync is ignored.
 	StartAsync bool
+
+	// Config is the configuration used for scouting, e.g. to select the
+	// multicast interface. Scouting consumes it. If nil, the default
+	// configuration is used.
+	Config *OwnedConfig
+
+	// OnError, if set, is called by Scout with the error that ends scouting
+	// in the background, such as a multicast socket that cannot be bound,
+	// before the channel is closed. ScoutBlocking returns it instead.
+	OnError func(error)
 }
 
 // DefaultScoutOptions returns default scouting options.
@@ -74,7 +115,7 @@ func DefaultScoutOptions() *ScoutOptions {
 // information about a discovered zenoh entity.
 type Hello struct {
 	// ZID is the Zenoh ID of the discovered entity.
-	ZID []byte
+	ZID ZenohID
 
 	// WhatAmI is the type of the discovered entity (router, peer, or client).
 	WhatAmI WhatAmI
@@ -85,7 +126,7 @@ type Hello struct {
 
 // IsValid returns true if the Hello contains valid information.
 func (h *Hello) IsValid() bool {
-	return h != nil && len(h.ZID) > 0
+	return h != nil && h.ZID.IsValid()
 }
 
 // String returns a string representation of the Hello.
@@ -93,7 +134,7 @@ func (h *Hello) String() string {
 	if h == nil {
 		return "Hello(nil)"
 	}
-	return "Hello{zid:" + string(h.ZID) + ", whatami:" + h.WhatAmI.String() + ", locators:" + formatLocators(h.Locators) + "}"
+	return "Hello{zid:" + h.ZID.String() + ", whatami:" + h.WhatAmI.String() + ", locators:" + formatLocators(h.Locators) + "}"
 }
 
 func formatLocators(locators []string) string {
@@ -114,13 +155,109 @@ func formatLocators(locators []string) string {
 // Scout - Dynamic Discovery
 // =============================================================================
 
+// helloFromCGO converts cgo hello data to a Hello.
+func helloFromCGO(data cgo.HelloData) *Hello {
+	return &Hello{
+		ZID:      ZenohID(data.ZID),
+		WhatAmI:  whatAmIFromCGO(data.WhatAmI),
+		Locators: data.Locators,
+	}
+}
+
+// scoutConfig validates opts and takes the configuration to scout with out
+// of it. The configuration is left untouched if opts are invalid.
+func (o *ScoutOptions) scoutConfig() (*cgo.Config, error) {
+	if o.Timeout < 0 || o.WhatAmI&^whatAmIAll != 0 {
+		return nil, ErrInvalidValue
+	}
+	if o.Config == nil {
+		return cgo.ConfigDefault()
+	}
+	if !o.Config.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	cfg := &cgo.Config{Ptr: o.Config.ptr}
+	cfg.SetOwnedPtr(o.Config.owned)
+	o.Config.ptr = 0
+	o.Config.owned = nil
+	return cfg, nil
+}
+
 // Scout performs dynamic discovery of peers and/or routers in the zenoh network.
 // This is equivalent to z_scout() in zenoh-c.
+//
+// The options are validated and the scouting configuration is set up before
+// Scout returns, so that setup errors are reported to the caller. Scouting then
+// runs in the background for opts.Timeout. Each discovered entity of a kind in
+// whatami or opts.WhatAmI is sent on the returned channel, which is closed when
+// scouting finishes. If the channel is full, the oldest hello is dropped so that a slow
+// reader never blocks zenoh. A failure of the background scouting is reported
+// to opts.OnError.
 func Scout(whatami WhatAmI, opts *ScoutOptions) (<-chan *Hello, error) {
-	return nil, errors.New("Scout requires zenoh-c bindings")
+	if whatami.what() == 0 {
+		return nil, ErrInvalidValue
+	}
+	if opts == nil {
+		opts = DefaultScoutOptions()
+	}
+	cfg, err := opts.scoutConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *Hello, 16)
+	timeoutMs := uint64(opts.Timeout.Milliseconds())
+	onError := opts.OnError
+	go func() {
+		defer close(ch)
+		var mu sync.Mutex
+		err := cgo.Scout(cfg, whatami.what()|opts.WhatAmI.what(), timeoutMs, func(data cgo.HelloData) {
+			mu.Lock()
+			defer mu.Unlock()
+			sendHello(ch, helloFromCGO(data))
+		})
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}()
+	return ch, nil
+}
+
+// sendHello sends hello on ch without blocking, dropping the oldest buffered
+// hello if ch is full. Calls must not run concurrently.
+func sendHello(ch chan *Hello, hello *Hello) {
+	select {
+	case ch <- hello:
+	default:
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- hello
+	}
 }
 
 // ScoutBlocking performs blocking discovery of peers and/or routers.
+// It returns every entity of a kind in whatami or opts.WhatAmI discovered
+// within opts.Timeout.
 func ScoutBlocking(whatami WhatAmI, opts *ScoutOptions) ([]*Hello, error) {
-	return nil, errors.New("ScoutBlocking requires zenoh-c bindings")
+	if whatami.what() == 0 {
+		return nil, ErrInvalidValue
+	}
+	if opts == nil {
+		opts = DefaultScoutOptions()
+	}
+	cfg, err := opts.scoutConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var hellos []*Hello
+	err = cgo.Scout(cfg, whatami.what()|opts.WhatAmI.what(), uint64(opts.Timeout.Milliseconds()), func(data cgo.HelloData) {
+		hellos = append(hellos, helloFromCGO(data))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hellos, nil
 }
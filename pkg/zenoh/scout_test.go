@@ -14,6 +14,9 @@ func TestWhatAmI_String(t *testing.T) {
 		{"router", WhatAmIRouter, "router"},
 		{"peer", WhatAmIPeer, "peer"},
 		{"client", WhatAmIClient, "client"},
+		{"router and peer", WhatAmIRouter | WhatAmIPeer, "router|peer"},
+		{"all", WhatAmIRouter | WhatAmIPeer | WhatAmIClient, "router|peer|client"},
+		{"none", WhatAmI(0), "unknown"},
 		{"unknown", WhatAmI(255), "unknown"},
 	}
 
@@ -40,6 +43,9 @@ func TestDefaultScoutOptions(t *testing.T) {
 	if opts.StartAsync != false {
 		t.Errorf("StartAsync = %v, want false", opts.StartAsync)
 	}
+	if opts.Config != nil {
+		t.Errorf("Config = %v, want nil", opts.Config)
+	}
 }
 
 func TestHello_IsValid(t *testing.T) {
@@ -49,8 +55,8 @@ func TestHello_IsValid(t *testing.T) {
 		wantErr bool
 	}{
 		{"nil hello", nil, false},
-		{"valid hello", &Hello{ZID: []byte("test"), WhatAmI: WhatAmIPeer, Locators: []string{"tcp/127.0.0.1:7447"}}, true},
-		{"empty zid", &Hello{ZID: []byte{}, WhatAmI: WhatAmIPeer}, false},
+		{"valid hello", &Hello{ZID: ZenohID{0x12}, WhatAmI: WhatAmIPeer, Locators: []string{"tcp/127.0.0.1:7447"}}, true},
+		{"empty zid", &Hello{ZID: ZenohID{}, WhatAmI: WhatAmIPeer}, false},
 	}
 
 	for _, tt := range tests {
@@ -70,8 +76,8 @@ func TestHello_String(t *testing.T) {
 		wantErr bool
 	}{
 		{"nil hello", nil, "Hello(nil)", false},
-		{"valid hello", &Hello{ZID: []byte("test"), WhatAmI: WhatAmIPeer, Locators: []string{"tcp/127.0.0.1:7447"}}, "Hello{zid:test, whatami:peer, locators:[tcp/127.0.0.1:7447]}", false},
-		{"empty locators", &Hello{ZID: []byte("test"), WhatAmI: WhatAmIRouter, Locators: []string{}}, "Hello{zid:test, whatami:router, locators:[]}", false},
+		{"valid hello", &Hello{ZID: ZenohID{0x34, 0x12}, WhatAmI: WhatAmIPeer, Locators: []string{"tcp/127.0.0.1:7447"}}, "Hello{zid:1234, whatami:peer, locators:[tcp/127.0.0.1:7447]}", false},
+		{"empty locators", &Hello{ZID: ZenohID{0x34, 0x12}, WhatAmI: WhatAmIRouter, Locators: []string{}}, "Hello{zid:1234, whatami:router, locators:[]}", false},
 	}
 
 	for _, tt := range tests {
@@ -84,56 +90,153 @@ func TestHello_String(t *testing.T) {
 	}
 }
 
-func TestScout(t *testing.T) {
-	t.Run("no options", func(t *testing.T) {
-		_, err := Scout(WhatAmIPeer, nil)
-		if err == nil {
-			t.Error("Scout() expected error (no zenoh-c bindings)")
-		}
-	})
+func TestScout_InvalidTarget(t *testing.T) {
+	_, err := Scout(WhatAmI(255), nil)
+	if err != ErrInvalidValue {
+		t.Errorf("Scout() error = %v, want %v", err, ErrInvalidValue)
+	}
 
-	t.Run("with options", func(t *testing.T) {
-		opts := DefaultScoutOptions()
-		opts.Timeout = time.Second
-		_, err := Scout(WhatAmIPeer, opts)
-		if err == nil {
-			t.Error("Scout() expected error (no zenoh-c bindings)")
-		}
-	})
+	_, err = ScoutBlocking(WhatAmI(255), nil)
+	if err != ErrInvalidValue {
+		t.Errorf("ScoutBlocking() error = %v, want %v", err, ErrInvalidValue)
+	}
+}
 
-	t.Run("router target", func(t *testing.T) {
-		_, err := Scout(WhatAmIRouter, nil)
-		if err == nil {
-			t.Error("Scout() expected error (no zenoh-c bindings)")
-		}
-	})
+func TestScout_InvalidConfig(t *testing.T) {
+	opts := DefaultScoutOptions()
+	opts.Config = &OwnedConfig{ptr: 0}
 
-	t.Run("client target", func(t *testing.T) {
-		_, err := Scout(WhatAmIClient, nil)
-		if err == nil {
-			t.Error("Scout() expected error (no zenoh-c bindings)")
-		}
-	})
+	_, err := Scout(WhatAmIPeer, opts)
+	if err != ErrInvalidValue {
+		t.Errorf("Scout() error = %v, want %v", err, ErrInvalidValue)
+	}
 }
 
-func TestScoutBlocking(t *testing.T) {
-	t.Run("no options", func(t *testing.T) {
-		_, err := ScoutBlocking(WhatAmIPeer, nil)
-		if err == nil {
-			t.Error("ScoutBlocking() expected error (no zenoh-c bindings)")
-		}
-	})
+func TestWhatAmI_what(t *testing.T) {
+	tests := []struct {
+		whatAmI WhatAmI
+		want    int
+	}{
+		{WhatAmIRouter, 1},
+		{WhatAmIPeer, 2},
+		{WhatAmIClient, 4},
+		{WhatAmIRouter | WhatAmIPeer, 3},
+		{WhatAmI(255), 0},
+	}
 
-	t.Run("with timeout", func(t *testing.T) {
-		opts := &ScoutOptions{
-			WhatAmI: WhatAmIPeer,
-			Timeout: 500 * time.Millisecond,
+	for _, tt := range tests {
+		t.Run(tt.whatAmI.String(), func(t *testing.T) {
+			if got := tt.whatAmI.what(); got != tt.want {
+				t.Errorf("WhatAmI.what() = %v, want %v", got, tt.want)
+			}
+			if tt.want != 0 {
+				if back := whatAmIFromCGO(tt.want); back != tt.whatAmI {
+					t.Errorf("whatAmIFromCGO(%d) = %v, want %v", tt.want, back, tt.whatAmI)
+				}
+			}
+		})
+	}
+}
+
+// openLoopbackPeer opens a peer session listening on the given localhost endpoint.
+func openLoopbackPeer(t *testing.T, endpoint string) *OwnedSession {
+	t.Helper()
+	config, err := NewDefaultConfig()
+	if err != nil {
+		t.Fatalf("NewDefaultConfig() error = %v", err)
+	}
+	config.InsertJSON5("mode", `"peer"`)
+	config.InsertJSON5("listen/endpoints", `["`+endpoint+`"]`)
+	config.InsertJSON5("scouting/multicast/interface", `"lo"`)
+	session, err := Open(config)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return session
+}
+
+func TestScoutBlocking_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c and multicast on the loopback interface")
+	}
+
+	peerA := openLoopbackPeer(t, "tcp/127.0.0.1:17447")
+	defer peerA.Drop()
+	peerB := openLoopbackPeer(t, "tcp/127.0.0.1:17448")
+	defer peerB.Drop()
+
+	config, err := NewDefaultConfig()
+	if err != nil {
+		t.Fatalf("NewDefaultConfig() error = %v", err)
+	}
+	config.InsertJSON5("scouting/multicast/interface", `"lo"`)
+
+	hellos, err := ScoutBlocking(WhatAmIPeer, &ScoutOptions{Timeout: 2 * time.Second, Config: config})
+	if err != nil {
+		t.Fatalf("ScoutBlocking() error = %v", err)
+	}
+	if config.IsValid() {
+		t.Error("scouting should consume the configuration")
+	}
+
+	found := map[string]bool{}
+	for _, hello := range hellos {
+		if !hello.IsValid() {
+			t.Errorf("invalid hello: %v", hello)
 		}
-		_, err := ScoutBlocking(WhatAmIPeer, opts)
-		if err == nil {
-			t.Error("ScoutBlocking() expected error (no zenoh-c bindings)")
+		if hello.WhatAmI != WhatAmIPeer {
+			t.Errorf("WhatAmI = %v, want %v", hello.WhatAmI, WhatAmIPeer)
 		}
-	})
+		for _, loc := range hello.Locators {
+			found[loc] = true
+		}
+	}
+	for _, want := range []string{"tcp/127.0.0.1:17447", "tcp/127.0.0.1:17448"} {
+		if !found[want] {
+			t.Errorf("peer listening on %s was not discovered, got %v", want, hellos)
+		}
+	}
+}
+
+func TestScout_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c and multicast on the loopback interface")
+	}
+
+	peer := openLoopbackPeer(t, "tcp/127.0.0.1:17449")
+	defer peer.Drop()
+
+	config, err := NewDefaultConfig()
+	if err != nil {
+		t.Fatalf("NewDefaultConfig() error = %v", err)
+	}
+	config.InsertJSON5("scouting/multicast/interface", `"lo"`)
+
+	ch, err := Scout(WhatAmIPeer, &ScoutOptions{Timeout: time.Second, Config: config})
+	if err != nil {
+		t.Fatalf("Scout() error = %v", err)
+	}
+
+	found := false
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case hello, ok := <-ch:
+			if !ok {
+				if !found {
+					t.Error("peer listening on tcp/127.0.0.1:17449 was not discovered")
+				}
+				return
+			}
+			for _, loc := range hello.Locators {
+				if loc == "tcp/127.0.0.1:17449" {
+					found = true
+				}
+			}
+		case <-deadline:
+			t.Fatal("Scout() channel was not closed after the timeout")
+		}
+	}
 }
 
 func TestScoutOptions_Validation(t *testing.T) {
@@ -153,11 +256,72 @@ func TestScoutOptions_Validation(t *testing.T) {
 			t.Error("StartAsync should be true")
 		}
 	})
+
+	for _, opts := range []*ScoutOptions{
+		{Timeout: -time.Second, Config: &OwnedConfig{ptr: 1}},
+		{WhatAmI: WhatAmI(8), Config: &OwnedConfig{ptr: 1}},
+	} {
+		if _, err := Scout(WhatAmIPeer, opts); err != ErrInvalidValue {
+			t.Errorf("Scout(%+v) error = %v, want %v", opts, err, ErrInvalidValue)
+		}
+		if _, err := ScoutBlocking(WhatAmIPeer, opts); err != ErrInvalidValue {
+			t.Errorf("ScoutBlocking(%+v) error = %v, want %v", opts, err, ErrInvalidValue)
+		}
+		if !opts.Config.IsValid() {
+			t.Error("invalid options should not consume the configuration")
+		}
+	}
+}
+
+func TestSendHello_DropsOldest(t *testing.T) {
+	ch := make(chan *Hello, 2)
+	hellos := []*Hello{{Locators: []string{"a"}}, {Locators: []string{"b"}}, {Locators: []string{"c"}}}
+	for _, hello := range hellos {
+		sendHello(ch, hello)
+	}
+	if got := <-ch; got != hellos[1] {
+		t.Errorf("first hello = %v, want %v", got, hellos[1])
+	}
+	if got := <-ch; got != hellos[2] {
+		t.Errorf("second hello = %v, want %v", got, hellos[2])
+	}
+}
+
+func TestScout_OnError_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	config, err := NewDefaultConfig()
+	if err != nil {
+		t.Fatalf("NewDefaultConfig() error = %v", err)
+	}
+	config.InsertJSON5("scouting/multicast/interface", `"no-such-interface"`)
+
+	errs := make(chan error, 1)
+	ch, err := Scout(WhatAmIPeer, &ScoutOptions{
+		Timeout: time.Second,
+		Config:  config,
+		OnError: func(err error) { errs <- err },
+	})
+	if err != nil {
+		t.Fatalf("Scout() error = %v", err)
+	}
+	for range ch {
+	}
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("OnError() called with a nil error")
+		}
+	default:
+		t.Error("OnError() not called for an interface that does not exist")
+	}
 }
 
 func TestHello_EmptyZID(t *testing.T) {
 	hello := &Hello{
-		ZID:      []byte{},
+		ZID:      ZenohID{},
 		WhatAmI:  WhatAmIPeer,
 		Locators: []string{},
 	}
@@ -165,13 +329,3 @@ func TestHello_EmptyZID(t *testing.T) {
 		t.Error("Hello with empty ZID should not be valid")
 	}
 }
-
-func TestScout_InvalidWhatAmI(t *testing.T) {
-	opts := DefaultScoutOptions()
-	opts.WhatAmI = WhatAmIClient
-
-	_, err := Scout(WhatAmIClient, opts)
-	if err == nil {
-		t.Error("Scout() expected error (no zenoh-c bindings)")
-	}
-}
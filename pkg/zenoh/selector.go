@@ -0,0 +1,347 @@
+package zenoh
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ParameterTime is the selector parameter that restricts a query to the
+	// values whose timestamp is within a TimeRange.
+	ParameterTime = "_time"
+	// ParameterAnyKeyExpr is the selector parameter that allows replies on key
+	// expressions that do not intersect the key expression of the query.
+	ParameterAnyKeyExpr = "_anyke"
+)
+
+// Selector is the target of a query: a key expression and the selector
+// parameters that follow it, as in "robot/*/status?unit=celsius".
+type Selector struct {
+	// KeyExpr is the key expression part of the selector.
+	KeyExpr string
+	// Parameters holds the parameters after the '?'. It may be nil when
+	// there are none.
+	Parameters *Parameters
+}
+
+// ParseSelector parses a selector in its "keyexpr?parameters" form.
+func ParseSelector(selector string) (Selector, error) {
+	keyExpr, params, err := parseSelector(selector)
+	if err != nil {
+		return Selector{}, err
+	}
+	return Selector{KeyExpr: keyExpr, Parameters: ParseParameters(params)}, nil
+}
+
+// String returns the selector in its "keyexpr?parameters" form. The '?' is
+// omitted when there are no parameters.
+func (s Selector) String() string {
+	if s.Parameters.Len() == 0 {
+		return s.KeyExpr
+	}
+	return s.KeyExpr + "?" + s.Parameters.String()
+}
+
+// Parameters is the ordered set of key/value parameters of a selector, as in
+// "unit=celsius;limit=10". Parameters are separated by ';' or '&', and a key
+// is separated from its value by the first '='; a key without '=' has an
+// empty value. A value may hold a list of items separated by '|'.
+//
+// The methods of a nil *Parameters behave as for empty parameters.
+type Parameters struct {
+	keys   []string
+	values map[string]string
+}
+
+// NewParameters returns empty parameters.
+func NewParameters() *Parameters {
+	return &Parameters{values: make(map[string]string)}
+}
+
+// ParseParameters parses the parameters part of a selector. Empty fields are
+// skipped and, as in zenoh, only the first occurrence of a key is kept.
+func ParseParameters(s string) *Parameters {
+	p := NewParameters()
+	for _, field := range strings.FieldsFunc(s, isParameterSeparator) {
+		key, value, _ := strings.Cut(field, "=")
+		if key == "" {
+			continue
+		}
+		if _, ok := p.values[key]; !ok {
+			p.keys = append(p.keys, key)
+			p.values[key] = value
+		}
+	}
+	return p
+}
+
+func isParameterSeparator(r rune) bool {
+	return r == ';' || r == '&'
+}
+
+// Len returns the number of parameters.
+func (p *Parameters) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.keys)
+}
+
+// Keys returns the keys of the parameters, in order.
+func (p *Parameters) Keys() []string {
+	if p == nil {
+		return nil
+	}
+	return append([]string(nil), p.keys...)
+}
+
+// Get returns the value of the parameter with the given key.
+func (p *Parameters) Get(key string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	value, ok := p.values[key]
+	return value, ok
+}
+
+// Values returns the '|'-separated items of the value of the parameter with
+// the given key, or nil if it is absent or empty.
+func (p *Parameters) Values(key string) []string {
+	value, ok := p.Get(key)
+	if !ok || value == "" {
+		return nil
+	}
+	return strings.Split(value, "|")
+}
+
+// Set sets the value of the parameter with the given key. A new key is added
+// after the existing ones; an existing key keeps its position. It fails with
+// ErrInvalidSelector if the key is empty or if the key or the value contains a
+// character that would change how the parameters are parsed.
+func (p *Parameters) Set(key, value string) error {
+	if p == nil || key == "" || strings.ContainsAny(key, ";&=") || strings.ContainsAny(value, ";&") {
+		return ErrInvalidSelector
+	}
+	if p.values == nil {
+		p.values = make(map[string]string)
+	}
+	if _, ok := p.values[key]; !ok {
+		p.keys = append(p.keys, key)
+	}
+	p.values[key] = value
+	return nil
+}
+
+// Delete removes the parameter with the given key.
+func (p *Parameters) Delete(key string) {
+	if p == nil {
+		return
+	}
+	if _, ok := p.values[key]; !ok {
+		return
+	}
+	delete(p.values, key)
+	for i, k := range p.keys {
+		if k == key {
+			p.keys = append(p.keys[:i], p.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// String encodes the parameters in order, separated by ';'. A parameter with
+// an empty value is encoded as its key alone, so parsing the result gives
+// back the same parameters.
+func (p *Parameters) String() string {
+	if p.Len() == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for i, key := range p.keys {
+		if i > 0 {
+			b.WriteByte(';')
+		}
+		b.WriteString(key)
+		if value := p.values[key]; value != "" {
+			b.WriteByte('=')
+			b.WriteString(value)
+		}
+	}
+	return b.String()
+}
+
+// ReplyKeyExprAny reports whether the _anyke parameter is set, allowing
+// replies on key expressions that do not intersect the one of the query.
+func (p *Parameters) ReplyKeyExprAny() bool {
+	_, ok := p.Get(ParameterAnyKeyExpr)
+	return ok
+}
+
+// SetReplyKeyExprAny sets or removes the _anyke parameter.
+func (p *Parameters) SetReplyKeyExprAny(enabled bool) {
+	if !enabled {
+		p.Delete(ParameterAnyKeyExpr)
+		return
+	}
+	p.Set(ParameterAnyKeyExpr, "")
+}
+
+// TimeRange returns the time range of the _time parameter, with relative
+// times such as now(-1h) resolved against the current time. ok is false if
+// the parameter is absent.
+func (p *Parameters) TimeRange() (r TimeRange, ok bool, err error) {
+	value, ok := p.Get(ParameterTime)
+	if !ok {
+		return TimeRange{}, false, nil
+	}
+	r, err = ParseTimeRange(value, time.Now())
+	return r, true, err
+}
+
+// SetTimeRange sets the _time parameter to the given range.
+func (p *Parameters) SetTimeRange(r TimeRange) error {
+	return p.Set(ParameterTime, r.String())
+}
+
+// TimeRange is the range of timestamps selected by the _time parameter.
+// A zero Start or End leaves the range unbounded on that side.
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+	// StartExclusive excludes Start itself from the range.
+	StartExclusive bool
+	// EndExclusive excludes End itself from the range.
+	EndExclusive bool
+}
+
+// ParseTimeRange parses a time range in the zenoh "[start..end]" format,
+// where '[' before the start or ']' after the end make the bound inclusive,
+// and ']' before the start or '[' after the end make it exclusive. A bound is
+// an RFC 3339 time, now() optionally shifted by a duration as in now(-1.5h),
+// or empty for an unbounded side. Durations are a number followed by one of
+// the units u, ms, s, m, h, d or w. Relative times are resolved against now.
+func ParseTimeRange(s string, now time.Time) (TimeRange, error) {
+	if len(s) < 2 {
+		return TimeRange{}, ErrInvalidSelector
+	}
+	var r TimeRange
+	switch s[0] {
+	case '[':
+	case ']':
+		r.StartExclusive = true
+	default:
+		return TimeRange{}, ErrInvalidSelector
+	}
+	switch s[len(s)-1] {
+	case ']':
+	case '[':
+		r.EndExclusive = true
+	default:
+		return TimeRange{}, ErrInvalidSelector
+	}
+	body := s[1 : len(s)-1]
+
+	start, end, ok := strings.Cut(body, "..")
+	if !ok {
+		return TimeRange{}, ErrInvalidSelector
+	}
+	var err error
+	if r.Start, err = parseTimeExpr(start, now); err != nil {
+		return TimeRange{}, err
+	}
+	if r.End, err = parseTimeExpr(end, now); err != nil {
+		return TimeRange{}, err
+	}
+	return r, nil
+}
+
+// parseTimeExpr parses one bound of a time range.
+func parseTimeExpr(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if offset, ok := strings.CutPrefix(s, "now("); ok {
+		offset, ok = strings.CutSuffix(offset, ")")
+		if !ok {
+			return time.Time{}, ErrInvalidSelector
+		}
+		if offset == "" {
+			return now, nil
+		}
+		d, err := parseTimeRangeDuration(offset)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, ErrInvalidSelector
+	}
+	return t, nil
+}
+
+// timeRangeUnits are the duration units of time ranges, longest first so
+// that "ms" is not read as "m".
+var timeRangeUnits = []struct {
+	suffix string
+	unit   time.Duration
+}{
+	{"ms", time.Millisecond},
+	{"u", time.Microsecond},
+	{"s", time.Second},
+	{"m", time.Minute},
+	{"h", time.Hour},
+	{"d", 24 * time.Hour},
+	{"w", 7 * 24 * time.Hour},
+}
+
+// parseTimeRangeDuration parses a duration such as "-1.5h" or "30s".
+func parseTimeRangeDuration(s string) (time.Duration, error) {
+	for _, u := range timeRangeUnits {
+		if number, ok := strings.CutSuffix(s, u.suffix); ok {
+			f, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, ErrInvalidSelector
+			}
+			return time.Duration(f * float64(u.unit)), nil
+		}
+	}
+	return 0, ErrInvalidSelector
+}
+
+// Contains reports whether t is within the range.
+func (r TimeRange) Contains(t time.Time) bool {
+	if !r.Start.IsZero() && (t.Before(r.Start) || r.StartExclusive && t.Equal(r.Start)) {
+		return false
+	}
+	if !r.End.IsZero() && (t.After(r.End) || r.EndExclusive && t.Equal(r.End)) {
+		return false
+	}
+	return true
+}
+
+// String encodes the range in the "[start..end]" form, with its bounds as
+// RFC 3339 times in UTC.
+func (r TimeRange) String() string {
+	var b strings.Builder
+	if r.StartExclusive {
+		b.WriteByte(']')
+	} else {
+		b.WriteByte('[')
+	}
+	if !r.Start.IsZero() {
+		b.WriteString(r.Start.UTC().Format(time.RFC3339Nano))
+	}
+	b.WriteString("..")
+	if !r.End.IsZero() {
+		b.WriteString(r.End.UTC().Format(time.RFC3339Nano))
+	}
+	if r.EndExclusive {
+		b.WriteByte('[')
+	} else {
+		b.WriteByte(']')
+	}
+	return b.String()
+}
@@ -0,0 +1,231 @@
+package zenoh
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		keyExpr  string
+		keys     []string
+		wantErr  bool
+	}{
+		{"demo/example", "demo/example", nil, false},
+		{"demo/*?unit=celsius", "demo/*", []string{"unit"}, false},
+		{"demo/**?a=1;b=2;_anyke", "demo/**", []string{"a", "b", "_anyke"}, false},
+		{"demo/q?", "demo/q", nil, false},
+		{"", "", nil, true},
+		{"?a=1", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, err := ParseSelector(tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if sel.KeyExpr != tt.keyExpr {
+				t.Errorf("KeyExpr = %q, want %q", sel.KeyExpr, tt.keyExpr)
+			}
+			if got := sel.Parameters.Keys(); !slices.Equal(got, tt.keys) {
+				t.Errorf("Parameters.Keys() = %v, want %v", got, tt.keys)
+			}
+		})
+	}
+}
+
+func TestSelector_StringRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"demo/example",
+		"demo/*?unit=celsius",
+		"demo/**?a=1;b=x|y|z;_anyke",
+		"demo/q?expr=a=b",
+		"demo/q?_time=[2024-01-01T00:00:00Z..]",
+	} {
+		sel, err := ParseSelector(s)
+		if err != nil {
+			t.Fatalf("ParseSelector(%q) error = %v", s, err)
+		}
+		if got := sel.String(); got != s {
+			t.Errorf("String() = %q, want %q", got, s)
+		}
+	}
+
+	if got := (Selector{KeyExpr: "demo/q"}).String(); got != "demo/q" {
+		t.Errorf("String() with nil Parameters = %q, want %q", got, "demo/q")
+	}
+}
+
+func TestParseParameters(t *testing.T) {
+	p := ParseParameters("a=1&b=2;;c;d=;e=x=y;a=3")
+	if got := p.String(); got != "a=1;b=2;c;d;e=x=y" {
+		t.Errorf("String() = %q", got)
+	}
+	tests := []struct {
+		key   string
+		value string
+		ok    bool
+	}{
+		{"a", "1", true},
+		{"b", "2", true},
+		{"c", "", true},
+		{"d", "", true},
+		{"e", "x=y", true},
+		{"f", "", false},
+	}
+	for _, tt := range tests {
+		if value, ok := p.Get(tt.key); value != tt.value || ok != tt.ok {
+			t.Errorf("Get(%q) = %q, %v, want %q, %v", tt.key, value, ok, tt.value, tt.ok)
+		}
+	}
+}
+
+func TestParameters_SetDelete(t *testing.T) {
+	p := NewParameters()
+	for _, kv := range [][2]string{{"a", "1"}, {"b", "2"}, {"c", "3"}, {"a", "4"}} {
+		if err := p.Set(kv[0], kv[1]); err != nil {
+			t.Fatalf("Set(%q, %q) error = %v", kv[0], kv[1], err)
+		}
+	}
+	if got := p.String(); got != "a=4;b=2;c=3" {
+		t.Errorf("String() = %q, want %q", got, "a=4;b=2;c=3")
+	}
+	p.Delete("b")
+	p.Delete("missing")
+	if got := p.String(); got != "a=4;c=3" {
+		t.Errorf("String() after Delete() = %q, want %q", got, "a=4;c=3")
+	}
+
+	for _, kv := range [][2]string{{"", "1"}, {"a;b", "1"}, {"a=b", "1"}, {"a", "1&b=2"}} {
+		if err := p.Set(kv[0], kv[1]); err != ErrInvalidSelector {
+			t.Errorf("Set(%q, %q) error = %v, want ErrInvalidSelector", kv[0], kv[1], err)
+		}
+	}
+
+	var nilParams *Parameters
+	if nilParams.Len() != 0 || nilParams.Keys() != nil || nilParams.String() != "" || nilParams.ReplyKeyExprAny() {
+		t.Error("nil Parameters should be empty")
+	}
+	if err := nilParams.Set("a", "1"); err != ErrInvalidSelector {
+		t.Errorf("Set() on nil error = %v, want ErrInvalidSelector", err)
+	}
+	nilParams.Delete("a")
+}
+
+func TestParameters_Values(t *testing.T) {
+	p := ParseParameters("ids=1|2|3;one=x;empty")
+	if got := p.Values("ids"); !slices.Equal(got, []string{"1", "2", "3"}) {
+		t.Errorf("Values(\"ids\") = %v", got)
+	}
+	if got := p.Values("one"); !slices.Equal(got, []string{"x"}) {
+		t.Errorf("Values(\"one\") = %v", got)
+	}
+	if p.Values("empty") != nil || p.Values("missing") != nil {
+		t.Error("Values() of an empty or missing parameter should be nil")
+	}
+}
+
+func TestParameters_ReplyKeyExprAny(t *testing.T) {
+	p := ParseParameters("a=1")
+	if p.ReplyKeyExprAny() {
+		t.Error("ReplyKeyExprAny() = true without _anyke")
+	}
+	p.SetReplyKeyExprAny(true)
+	if !p.ReplyKeyExprAny() || p.String() != "a=1;_anyke" {
+		t.Errorf("after SetReplyKeyExprAny(true): %q", p.String())
+	}
+	p.SetReplyKeyExprAny(false)
+	if p.ReplyKeyExprAny() || p.String() != "a=1" {
+		t.Errorf("after SetReplyKeyExprAny(false): %q", p.String())
+	}
+}
+
+func TestParseTimeRange(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		s       string
+		want    TimeRange
+		wantErr bool
+	}{
+		{"[..]", TimeRange{}, false},
+		{"[2024-01-01T00:00:00Z..now()]", TimeRange{Start: start, End: now}, false},
+		{"]now(-1.5h)..now(30s)[", TimeRange{Start: now.Add(-90 * time.Minute), End: now.Add(30 * time.Second), StartExclusive: true, EndExclusive: true}, false},
+		{"[now(-2d)..]", TimeRange{Start: now.Add(-48 * time.Hour)}, false},
+		{"[now(-1w)..now(-500ms)]", TimeRange{Start: now.Add(-7 * 24 * time.Hour), End: now.Add(-500 * time.Millisecond)}, false},
+		{"[now(-10u)..]", TimeRange{Start: now.Add(-10 * time.Microsecond)}, false},
+		{"", TimeRange{}, true},
+		{"2024-01-01T00:00:00Z..", TimeRange{}, true},
+		{"[2024-01-01..]", TimeRange{}, true},
+		{"[now(-1y)..]", TimeRange{}, true},
+		{"[now(-1h..]", TimeRange{}, true},
+		{"[now()]", TimeRange{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseTimeRange(tt.s, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimeRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Start.Equal(tt.want.Start) || !got.End.Equal(tt.want.End) ||
+				got.StartExclusive != tt.want.StartExclusive || got.EndExclusive != tt.want.EndExclusive {
+				t.Errorf("ParseTimeRange() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeRange_Contains(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	r := TimeRange{Start: start, End: end}
+	if !r.Contains(start) || !r.Contains(end) || r.Contains(end.Add(1)) || r.Contains(start.Add(-1)) {
+		t.Error("inclusive range bounds are wrong")
+	}
+	r.StartExclusive, r.EndExclusive = true, true
+	if r.Contains(start) || r.Contains(end) || !r.Contains(start.Add(time.Minute)) {
+		t.Error("exclusive range bounds are wrong")
+	}
+	if !(TimeRange{}).Contains(start) {
+		t.Error("unbounded range should contain every time")
+	}
+}
+
+func TestParameters_TimeRange(t *testing.T) {
+	p := NewParameters()
+	if _, ok, err := p.TimeRange(); ok || err != nil {
+		t.Errorf("TimeRange() without _time = %v, %v", ok, err)
+	}
+
+	want := TimeRange{
+		Start:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:          time.Date(2024, 1, 2, 0, 0, 0, 500, time.UTC),
+		EndExclusive: true,
+	}
+	if err := p.SetTimeRange(want); err != nil {
+		t.Fatalf("SetTimeRange() error = %v", err)
+	}
+	if got := p.String(); got != "_time=[2024-01-01T00:00:00Z..2024-01-02T00:00:00.0000005Z[" {
+		t.Errorf("String() = %q", got)
+	}
+	got, ok, err := ParseParameters(p.String()).TimeRange()
+	if !ok || err != nil {
+		t.Fatalf("TimeRange() = %v, %v", ok, err)
+	}
+	if !got.Start.Equal(want.Start) || !got.End.Equal(want.End) || got.EndExclusive != want.EndExclusive {
+		t.Errorf("TimeRange() = %+v, want %+v", got, want)
+	}
+
+	if _, ok, err := ParseParameters("_time=yesterday").TimeRange(); !ok || err != ErrInvalidSelector {
+		t.Errorf("TimeRange() of an invalid range = %v, %v", ok, err)
+	}
+}
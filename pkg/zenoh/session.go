@@ -2,6 +2,8 @@ package zenoh
 
 import (
 	"log"
+	"math"
+	"time"
 
 	"github.com/wind-c/zenoh-go/internal/cgo"
 )
@@ -22,3 +24,240 @@ func Open(config *OwnedConfig) (*OwnedSession, error) {
 	}
 	return &OwnedSession{ptr: s.Ptr, owned: s.OwnedPtr()}, nil
 }
+
+// FromOwnedSession creates a Session from an existing owned session.
+// The returned Session is a loaned reference that does not own resources.
+func FromOwnedSession(owned *OwnedSession) (*Session, error) {
+	if owned == nil || !owned.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	return &Session{ptr: owned.ptr}, nil
+}
+
+// Drop gracefully closes the session with the default timeout and releases
+// the underlying zenoh session resources.
+// After calling Drop, the OwnedSession is invalidated and further calls
+// on it return ErrInvalidValue.
+//
+// It is safe to call Drop multiple times; subsequent calls are no-ops.
+func (s *OwnedSession) Drop() error {
+	return s.DropWithTimeout(0)
+}
+
+// DropWithTimeout is like Drop but waits at most timeout for the graceful
+// close to complete. A zero or negative timeout uses the zenoh default of 10
+// seconds. zenoh counts the timeout in milliseconds: a shorter non-zero timeout
+// is rounded up to one millisecond, and timeouts are capped at math.MaxUint32
+// milliseconds, about 49 days.
+// Publishers, subscribers and queryables still declared on the session are
+// undeclared by the close. The session is released even if closing fails.
+// This is equivalent to z_close() followed by z_session_drop() in zenoh-c.
+func (s *OwnedSession) DropWithTimeout(timeout time.Duration) error {
+	if !s.IsValid() {
+		return nil
+	}
+	session := cgo.SessionFromOwnedPtr(s.ptr, s.owned)
+	s.ptr = 0
+	s.owned = nil
+	return session.CloseWithTimeout(closeTimeoutMs(timeout))
+}
+
+// closeTimeoutMs converts a close timeout to the milliseconds of z_close_options_t,
+// in which 0 selects the zenoh default.
+func closeTimeoutMs(timeout time.Duration) uint32 {
+	if timeout <= 0 {
+		return 0
+	}
+	ms := timeout / time.Millisecond
+	if timeout%time.Millisecond != 0 {
+		ms++
+	}
+	if ms > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(ms)
+}
+
+// Close closes the zenoh session. All entities declared on it are undeclared
+// and further operations on the session fail. The owning OwnedSession must
+// still be dropped to release its resources.
+// This is equivalent to z_close() in zenoh-c.
+func (s *Session) Close() error {
+	if !s.IsValid() {
+		return nil
+	}
+	session := cgo.SessionFromPtr(s.ptr)
+	s.ptr = 0
+	return session.Shutdown(0)
+}
+
+// Info returns the ZID of the session along with the ZIDs of the peers
+// and routers it is currently connected to.
+// This is equivalent to z_info_zid(), z_info_peers_zid() and z_info_routers_zid() in zenoh-c.
+func (s *OwnedSession) Info() (*SessionInfo, error) {
+	if s == nil || !s.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	session := cgo.SessionFromOwnedPtr(s.ptr, s.owned)
+	peers, err := session.PeersZID()
+	if err != nil {
+		return nil, err
+	}
+	routers, err := session.RoutersZID()
+	if err != nil {
+		return nil, err
+	}
+	info := &SessionInfo{ZID: ZenohID(session.ZID())}
+	for _, zid := range peers {
+		info.Peers = append(info.Peers, ZenohID(zid))
+	}
+	for _, zid := range routers {
+		info.Routers = append(info.Routers, ZenohID(zid))
+	}
+	return info, nil
+}
+
+// NewTimestamp returns a new timestamp from the session clock.
+// This is equivalent to z_timestamp_new() in zenoh-c.
+func (s *OwnedSession) NewTimestamp() (*ZenohTimestamp, error) {
+	if s == nil || !s.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	session := cgo.SessionFromOwnedPtr(s.ptr, s.owned)
+	ts, err := session.NewTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	return zenohTimestampFromCGO(ts), nil
+}
+
+// PutOptions contains options for session-level Put operations.
+type PutOptions struct {
+	// Encoding specifies the encoding of the payload.
+	Encoding *Encoding
+	// CongestionControl, if set, specifies the congestion control mode.
+	// nil keeps the zenoh default, CongestionControlDrop.
+	CongestionControl *CongestionControl
+	// Priority specifies the message priority. Zero selects PriorityDefault.
+	Priority Priority
+	// IsExpress disables batching for this message, trading throughput for latency.
+	IsExpress bool
+	// AllowedDestination restricts the sessions this message may reach.
+	AllowedDestination Locality
+	// Attachment is optional user metadata sent alongside the payload.
+	Attachment *Attachment
+	// Timestamp, if set, is sent instead of a timestamp from the session clock.
+	Timestamp *ZenohTimestamp
+}
+
+// DefaultPutOptions returns default put options.
+func DefaultPutOptions() *PutOptions {
+	return &PutOptions{
+		Priority: PriorityDefault,
+	}
+}
+
+// DeleteOptions contains options for session-level Delete operations.
+// zenoh-c does not carry attachments on deletes, so there is no Attachment option.
+type DeleteOptions struct {
+	// CongestionControl, if set, specifies the congestion control mode.
+	// nil keeps the zenoh default, CongestionControlDrop.
+	CongestionControl *CongestionControl
+	// Priority specifies the message priority. Zero selects PriorityDefault.
+	Priority Priority
+	// IsExpress disables batching for this message, trading throughput for latency.
+	IsExpress bool
+	// AllowedDestination restricts the sessions this message may reach.
+	AllowedDestination Locality
+	// Timestamp, if set, is sent instead of a timestamp from the session clock.
+	Timestamp *ZenohTimestamp
+}
+
+// DefaultDeleteOptions returns default delete options.
+func DefaultDeleteOptions() *DeleteOptions {
+	return &DeleteOptions{
+		Priority: PriorityDefault,
+	}
+}
+
+// Put publishes a single value on the given key expression without declaring a publisher.
+// This is equivalent to z_put() in zenoh-c.
+func (s *OwnedSession) Put(keyExpr string, payload []byte, opts *PutOptions) error {
+	if s == nil || !s.IsValid() {
+		return ErrInvalidValue
+	}
+	if keyExpr == "" {
+		return ErrInvalidKeyExpr
+	}
+	return s.put(cgo.KeyExprArg{Expr: keyExpr}, payload, opts)
+}
+
+// PutKeyExpr is like Put, but publishes on a key expression declared with
+// DeclareKeyExpr, so that the message carries its numeric ID.
+// This is equivalent to z_put() in zenoh-c.
+func (s *OwnedSession) PutKeyExpr(keyExpr *OwnedKeyExpr, payload []byte, opts *PutOptions) error {
+	if s == nil || !s.IsValid() || !keyExpr.IsValid() {
+		return ErrInvalidValue
+	}
+	return s.put(keyExpr.toCGO(), payload, opts)
+}
+
+func (s *OwnedSession) put(keyExpr cgo.KeyExprArg, payload []byte, opts *PutOptions) error {
+	if opts == nil {
+		opts = DefaultPutOptions()
+	}
+	session := cgo.SessionFromOwnedPtr(s.ptr, s.owned)
+	return session.Put(keyExpr, payload, opts.toCGO())
+}
+
+func (o *PutOptions) toCGO() *cgo.PutOptions {
+	return &cgo.PutOptions{
+		Encoding:           o.Encoding.toCGO(),
+		CongestionControl:  o.CongestionControl.toCGO(),
+		Priority:           int(o.Priority),
+		IsExpress:          o.IsExpress,
+		AllowedDestination: int(o.AllowedDestination),
+		Attachment:         o.Attachment.toCGO(),
+		Timestamp:          o.Timestamp.toCGO(),
+	}
+}
+
+// Delete sends a DELETE on the given key expression without declaring a publisher.
+// This is equivalent to z_delete() in zenoh-c.
+func (s *OwnedSession) Delete(keyExpr string, opts *DeleteOptions) error {
+	if s == nil || !s.IsValid() {
+		return ErrInvalidValue
+	}
+	if keyExpr == "" {
+		return ErrInvalidKeyExpr
+	}
+	return s.delete(cgo.KeyExprArg{Expr: keyExpr}, opts)
+}
+
+// DeleteKeyExpr is like Delete, but on a key expression declared with
+// DeclareKeyExpr, so that the message carries its numeric ID.
+// This is equivalent to z_delete() in zenoh-c.
+func (s *OwnedSession) DeleteKeyExpr(keyExpr *OwnedKeyExpr, opts *DeleteOptions) error {
+	if s == nil || !s.IsValid() || !keyExpr.IsValid() {
+		return ErrInvalidValue
+	}
+	return s.delete(keyExpr.toCGO(), opts)
+}
+
+func (s *OwnedSession) delete(keyExpr cgo.KeyExprArg, opts *DeleteOptions) error {
+	if opts == nil {
+		opts = DefaultDeleteOptions()
+	}
+	session := cgo.SessionFromOwnedPtr(s.ptr, s.owned)
+	return session.Delete(keyExpr, opts.toCGO())
+}
+
+func (o *DeleteOptions) toCGO() *cgo.DeleteOptions {
+	return &cgo.DeleteOptions{
+		CongestionControl:  o.CongestionControl.toCGO(),
+		Priority:           int(o.Priority),
+		IsExpress:          o.IsExpress,
+		AllowedDestination: int(o.AllowedDestination),
+		Timestamp:          o.Timestamp.toCGO(),
+	}
+}
@@ -1,7 +1,9 @@
 package zenoh
 
 import (
+	"math"
 	"testing"
+	"time"
 )
 
 func TestOpen(t *testing.T) {
@@ -41,3 +43,211 @@ func TestOwnedSession_Info(t *testing.T) {
 		}
 	})
 }
+
+func TestOwnedSession_NewTimestamp(t *testing.T) {
+	var s *OwnedSession
+	if _, err := s.NewTimestamp(); err != ErrInvalidValue {
+		t.Errorf("NewTimestamp() on nil session error = %v, want %v", err, ErrInvalidValue)
+	}
+	if _, err := (&OwnedSession{}).NewTimestamp(); err != ErrInvalidValue {
+		t.Errorf("NewTimestamp() on invalid session error = %v, want %v", err, ErrInvalidValue)
+	}
+}
+
+func TestOwnedSession_Put(t *testing.T) {
+	tests := []struct {
+		name    string
+		session *OwnedSession
+		keyExpr string
+		wantErr error
+	}{
+		{"nil session", nil, "demo/test", ErrInvalidValue},
+		{"invalid session", &OwnedSession{ptr: 0}, "demo/test", ErrInvalidValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.session.Put(tt.keyExpr, []byte("test"), nil)
+			if err != tt.wantErr {
+				t.Errorf("Put() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOwnedSession_Delete(t *testing.T) {
+	tests := []struct {
+		name    string
+		session *OwnedSession
+		keyExpr string
+		wantErr error
+	}{
+		{"nil session", nil, "demo/test", ErrInvalidValue},
+		{"invalid session", &OwnedSession{ptr: 0}, "demo/test", ErrInvalidValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.session.Delete(tt.keyExpr, nil)
+			if err != tt.wantErr {
+				t.Errorf("Delete() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultPutOptions(t *testing.T) {
+	opts := DefaultPutOptions()
+	if opts.CongestionControl != nil {
+		t.Errorf("CongestionControl = %v, want nil", *opts.CongestionControl)
+	}
+	if opts.Priority != PriorityDefault {
+		t.Errorf("Priority = %v, want %v", opts.Priority, PriorityDefault)
+	}
+	if opts.IsExpress {
+		t.Error("IsExpress should be false by default")
+	}
+}
+
+func TestDefaultDeleteOptions(t *testing.T) {
+	opts := DefaultDeleteOptions()
+	if opts.CongestionControl != nil {
+		t.Errorf("CongestionControl = %v, want nil", *opts.CongestionControl)
+	}
+	if opts.Priority != PriorityDefault {
+		t.Errorf("Priority = %v, want %v", opts.Priority, PriorityDefault)
+	}
+}
+
+func TestPutOptions_toCGO(t *testing.T) {
+	if cc := (&PutOptions{}).toCGO().CongestionControl; cc != nil {
+		t.Errorf("zero PutOptions CongestionControl = %d, want nil", *cc)
+	}
+	if cc := (&DeleteOptions{}).toCGO().CongestionControl; cc != nil {
+		t.Errorf("zero DeleteOptions CongestionControl = %d, want nil", *cc)
+	}
+
+	block := CongestionControlBlock
+	opts := &PutOptions{CongestionControl: &block}
+	if cc := opts.toCGO().CongestionControl; cc == nil || *cc != int(CongestionControlBlock) {
+		t.Errorf("CongestionControl = %v, want %d", cc, CongestionControlBlock)
+	}
+}
+
+func TestOwnedSession_PutDefaultCongestionControl_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	session := openLoopbackPeer(t, "tcp/127.0.0.1:17460")
+	defer session.Drop()
+
+	samples := make(chan Sample, 1)
+	sub, err := DeclareSubscriber(session, "demo/congestion", func(s Sample) { samples <- s })
+	if err != nil {
+		t.Fatalf("DeclareSubscriber() error = %v", err)
+	}
+	defer sub.Drop()
+
+	if err := session.Put("demo/congestion", []byte("x"), &PutOptions{Attachment: NewAttachment([]byte("a"))}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	select {
+	case s := <-samples:
+		if s.CongestionControl != CongestionControlDrop {
+			t.Errorf("CongestionControl = %v, want %v", s.CongestionControl, CongestionControlDrop)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no sample received")
+	}
+}
+
+func TestOwnedSession_PutTimestamp_Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	session := openLoopbackPeer(t, "tcp/127.0.0.1:17461")
+	defer session.Drop()
+
+	samples := make(chan Sample, 1)
+	sub, err := DeclareSubscriber(session, "demo/timestamp", func(s Sample) { samples <- s })
+	if err != nil {
+		t.Fatalf("DeclareSubscriber() error = %v", err)
+	}
+	defer sub.Drop()
+
+	ts, err := session.NewTimestamp()
+	if err != nil {
+		t.Fatalf("NewTimestamp() error = %v", err)
+	}
+	if err := session.Put("demo/timestamp", []byte("x"), &PutOptions{Timestamp: ts}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	select {
+	case s := <-samples:
+		if s.Timestamp == nil || s.Timestamp.Compare(ts.Timestamp()) != 0 {
+			t.Errorf("Timestamp = %v, want %v", s.Timestamp, ts)
+		}
+		if s.ZenohTimestamp() == nil || s.ZenohTimestamp().Timestamp() != ts.Timestamp() {
+			t.Errorf("ZenohTimestamp() = %v, want %v", s.ZenohTimestamp(), ts)
+		}
+		if err := session.Put("demo/timestamp", []byte("y"), &PutOptions{Timestamp: s.ZenohTimestamp()}); err != nil {
+			t.Errorf("Put() with a received timestamp error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no sample received")
+	}
+}
+
+func TestCloseTimeoutMs(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		want    uint32
+	}{
+		{0, 0},
+		{-time.Second, 0},
+		{500 * time.Microsecond, 1},
+		{time.Nanosecond, 1},
+		{time.Millisecond, 1},
+		{1500 * time.Microsecond, 2},
+		{10 * time.Second, 10000},
+		{50 * 24 * time.Hour, math.MaxUint32},
+		{time.Duration(math.MaxInt64), math.MaxUint32},
+	}
+
+	for _, tt := range tests {
+		if got := closeTimeoutMs(tt.timeout); got != tt.want {
+			t.Errorf("closeTimeoutMs(%v) = %d, want %d", tt.timeout, got, tt.want)
+		}
+	}
+}
+
+func TestOwnedSession_DropInvalidates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires zenoh-c")
+	}
+
+	session := openLoopbackPeer(t, "tcp/127.0.0.1:17450")
+	pub, err := DeclarePublisherWithKeyExpr(session, "demo/drop/test")
+	if err != nil {
+		t.Fatalf("DeclarePublisherWithKeyExpr() error = %v", err)
+	}
+	defer pub.Drop()
+
+	if err := session.DropWithTimeout(time.Second); err != nil {
+		t.Fatalf("DropWithTimeout() error = %v", err)
+	}
+	if session.IsValid() {
+		t.Error("session should be invalid after Drop()")
+	}
+	if err := session.Put("demo/drop/test", []byte("x"), nil); err != ErrInvalidValue {
+		t.Errorf("Put() after Drop() error = %v, want %v", err, ErrInvalidValue)
+	}
+	if _, err := session.Info(); err != ErrInvalidValue {
+		t.Errorf("Info() after Drop() error = %v, want %v", err, ErrInvalidValue)
+	}
+	if err := session.Drop(); err != nil {
+		t.Errorf("second Drop() error = %v", err)
+	}
+}
@@ -10,10 +10,46 @@ import (
 var ErrInvalidSubscriber = errors.New("invalid subscriber")
 
 // Sample represents a zenoh sample received from a subscription.
+// Timestamp, SourceInfo and Attachment are nil when the sample carries none.
 type Sample struct {
-	KeyExpr  string
-	Payload  []byte
-	Encoding *Encoding
+	KeyExpr           string
+	Payload           []byte
+	Encoding          *Encoding
+	Kind              SampleKind
+	Timestamp         *Timestamp
+	Priority          Priority
+	CongestionControl CongestionControl
+	Express           bool
+	SourceInfo        *SourceInfo
+	Attachment        *Attachment
+
+	zenohTimestamp *ZenohTimestamp
+}
+
+func sampleFromCGO(sample cgo.SampleData) Sample {
+	s := Sample{
+		KeyExpr:           sample.KeyExpr,
+		Payload:           sample.Payload,
+		Encoding:          EncodingFromStr(sample.Encoding),
+		Kind:              SampleKind(sample.Kind),
+		Priority:          Priority(sample.Priority),
+		CongestionControl: CongestionControl(sample.CongestionControl),
+		Express:           sample.Express,
+		Timestamp:         timestampFromCGO(sample.Timestamp),
+		Attachment:        attachmentFromCGO(sample.Attachment),
+		zenohTimestamp:    zenohTimestampFromCGO(sample.Timestamp),
+	}
+	if info := sample.SourceInfo; info != nil {
+		s.SourceInfo = &SourceInfo{ZID: ZenohID(info.ZID), EID: info.EID, SN: info.SN}
+	}
+	return s
+}
+
+// ZenohTimestamp returns the timestamp of the sample in the form that can be
+// sent again, for instance when replying with a stored sample. It is nil when
+// the sample carries no timestamp.
+func (s *Sample) ZenohTimestamp() *ZenohTimestamp {
+	return s.zenohTimestamp
 }
 
 func (s *Sample) String() string {
@@ -89,19 +125,15 @@ func DeclareSubscriber(session *OwnedSession, keyExpr string, callback Subscribe
 	s := cgo.SessionFromOwnedPtr(session.ptr, session.owned)
 
 	cgoCallback := func(sample cgo.SampleData) {
-		callback(Sample{
-			KeyExpr:  sample.KeyExpr,
-			Payload:  sample.Payload,
-			Encoding: nil,
-		})
+		callback(sampleFromCGO(sample))
 	}
 
-	sub, err := s.DeclareSubscriber(keyExpr, cgoCallback)
+	sub, err := s.DeclareSubscriber(cgo.KeyExprArg{Expr: keyExpr}, cgoCallback)
 	if err != nil {
 		return nil, err
 	}
 
-	return &OwnedSubscriber{ptr: sub.Ptr}, nil
+	return &OwnedSubscriber{ptr: sub.Ptr, owned: sub.OwnedPtr()}, nil
 }
 
 // SubscriberOptions contains options for Subscriber declaration.
@@ -128,6 +160,25 @@ func DeclareSubscriberWithOptions(session *OwnedSession, keyExpr string, callbac
 	if callback == nil {
 		return nil, errors.New("callback cannot be nil")
 	}
+	return declareSubscriber(session, cgo.KeyExprArg{Expr: keyExpr}, callback, opts)
+}
+
+// DeclareSubscriberKeyExpr is like DeclareSubscriberWithOptions, but on a key
+// expression declared with OwnedSession.DeclareKeyExpr.
+func DeclareSubscriberKeyExpr(session *OwnedSession, keyExpr *OwnedKeyExpr, callback SubscriberCallback, opts *SubscriberOptions) (*OwnedSubscriber, error) {
+	if session == nil || !session.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	if !keyExpr.IsValid() {
+		return nil, ErrInvalidValue
+	}
+	if callback == nil {
+		return nil, errors.New("callback cannot be nil")
+	}
+	return declareSubscriber(session, keyExpr.toCGO(), callback, opts)
+}
+
+func declareSubscriber(session *OwnedSession, keyExpr cgo.KeyExprArg, callback SubscriberCallback, opts *SubscriberOptions) (*OwnedSubscriber, error) {
 	if opts == nil {
 		opts = DefaultSubscriberOptions()
 	}
@@ -135,11 +186,7 @@ func DeclareSubscriberWithOptions(session *OwnedSession, keyExpr string, callbac
 	s := cgo.SessionFromOwnedPtr(session.ptr, session.owned)
 
 	cgoCallback := func(sample cgo.SampleData) {
-		callback(Sample{
-			KeyExpr:  sample.KeyExpr,
-			Payload:  sample.Payload,
-			Encoding: nil,
-		})
+		callback(sampleFromCGO(sample))
 	}
 
 	sub, err := s.DeclareSubscriberWithOptions(keyExpr, cgoCallback, int(opts.Reliability))
@@ -147,5 +194,5 @@ func DeclareSubscriberWithOptions(session *OwnedSession, keyExpr string, callbac
 		return nil, err
 	}
 
-	return &OwnedSubscriber{ptr: sub.Ptr}, nil
+	return &OwnedSubscriber{ptr: sub.Ptr, owned: sub.OwnedPtr()}, nil
 }
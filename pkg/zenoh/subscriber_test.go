@@ -2,6 +2,8 @@ package zenoh
 
 import (
 	"testing"
+
+	"github.com/wind-c/zenoh-go/internal/cgo"
 )
 
 func TestDeclareSubscriberWithCallback(t *testing.T) {
@@ -109,6 +111,55 @@ func TestSample(t *testing.T) {
 	}
 }
 
+func TestSampleFromCGO(t *testing.T) {
+	t.Run("full metadata", func(t *testing.T) {
+		s := sampleFromCGO(cgo.SampleData{
+			KeyExpr:           "demo/test",
+			Payload:           []byte("hello"),
+			Encoding:          "text/plain",
+			Kind:              1,
+			Timestamp:         &cgo.TimestampData{NTP64: 42, ID: [cgo.ZIDSize]byte{1}},
+			Priority:          int(PriorityRealTime),
+			CongestionControl: int(CongestionControlBlock),
+			Express:           true,
+			SourceInfo:        &cgo.SourceInfoData{ZID: [cgo.ZIDSize]byte{2}, EID: 3, SN: 4},
+			Attachment:        []byte("meta"),
+		})
+
+		if s.Kind != SampleKindDelete {
+			t.Errorf("Kind = %v, want %v", s.Kind, SampleKindDelete)
+		}
+		if s.Timestamp == nil || s.Timestamp.NTP64 != 42 || s.Timestamp.ID != (ZenohID{1}) {
+			t.Errorf("Timestamp = %+v, want {42 1}", s.Timestamp)
+		}
+		if s.Priority != PriorityRealTime {
+			t.Errorf("Priority = %v, want %v", s.Priority, PriorityRealTime)
+		}
+		if s.CongestionControl != CongestionControlBlock {
+			t.Errorf("CongestionControl = %v, want %v", s.CongestionControl, CongestionControlBlock)
+		}
+		if !s.Express {
+			t.Error("Express should be true")
+		}
+		if s.SourceInfo == nil || *s.SourceInfo != (SourceInfo{ZID: ZenohID{2}, EID: 3, SN: 4}) {
+			t.Errorf("SourceInfo = %+v, want {2 3 4}", s.SourceInfo)
+		}
+		if string(s.Attachment.Bytes()) != "meta" {
+			t.Errorf("Attachment = %q, want %q", s.Attachment.Bytes(), "meta")
+		}
+	})
+
+	t.Run("no optional metadata", func(t *testing.T) {
+		s := sampleFromCGO(cgo.SampleData{KeyExpr: "demo/test"})
+		if s.Kind != SampleKindPut {
+			t.Errorf("Kind = %v, want %v", s.Kind, SampleKindPut)
+		}
+		if s.Timestamp != nil || s.SourceInfo != nil || s.Attachment != nil {
+			t.Error("optional metadata should be nil")
+		}
+	})
+}
+
 func TestRingChannel(t *testing.T) {
 	t.Run("create with zero size", func(t *testing.T) {
 		ch := NewRingChannel(0)
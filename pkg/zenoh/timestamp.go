@@ -0,0 +1,149 @@
+package zenoh
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wind-c/zenoh-go/internal/cgo"
+)
+
+// ErrInvalidTimestamp is returned when a string is not a valid Timestamp.
+var ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+// Timestamp is a zenoh Hybrid Logical Clock timestamp: a time in NTP64
+// format and the ID of the zenoh node whose clock produced it.
+//
+// Timestamps are totally ordered, first by time and then by ID, so that
+// timestamps produced by different nodes never compare equal. This makes
+// them suitable for last-writer-wins ordering across nodes.
+type Timestamp struct {
+	// NTP64 holds the seconds since the Unix epoch in its upper 32 bits
+	// and the fraction of second in its lower 32 bits.
+	NTP64 uint64
+	// ID is the ZenohID of the node that produced the timestamp.
+	ID ZenohID
+}
+
+// ZenohTimestamp is a timestamp created by zenoh, as returned by
+// OwnedSession.NewTimestamp or Sample.ZenohTimestamp. Unlike a Timestamp, it
+// can be set on put, delete and reply options.
+//
+// zenoh-c only creates timestamps from a session clock and cannot build one
+// from a time and an ID, so a Timestamp built with TimestampFromTime or
+// ParseTimestamp, for instance when restoring it from storage, cannot be sent.
+type ZenohTimestamp struct {
+	data cgo.TimestampData
+}
+
+// Timestamp returns the time and ID of the timestamp.
+func (t *ZenohTimestamp) Timestamp() Timestamp {
+	return Timestamp{NTP64: t.data.NTP64, ID: ZenohID(t.data.ID)}
+}
+
+// String returns the timestamp in the same format as Timestamp.String.
+func (t *ZenohTimestamp) String() string {
+	return t.Timestamp().String()
+}
+
+func (t *ZenohTimestamp) toCGO() *cgo.TimestampData {
+	if t == nil {
+		return nil
+	}
+	data := t.data
+	return &data
+}
+
+func zenohTimestampFromCGO(ts *cgo.TimestampData) *ZenohTimestamp {
+	if ts == nil {
+		return nil
+	}
+	return &ZenohTimestamp{data: *ts}
+}
+
+const (
+	ntp64FracPerSec = 1 << 32
+	nanosPerSec     = uint64(time.Second)
+)
+
+// TimestampFromTime creates a timestamp for t attributed to id, converting
+// the time to NTP64 as zenoh does. Times before the Unix epoch are clamped to
+// the epoch.
+func TimestampFromTime(t time.Time, id ZenohID) Timestamp {
+	if t.Before(time.Unix(0, 0)) {
+		return Timestamp{ID: id}
+	}
+	secs := uint64(t.Unix())
+	nanos := uint64(t.Nanosecond())
+	frac := nanos * ntp64FracPerSec / nanosPerSec
+	return Timestamp{NTP64: secs<<32 + frac, ID: id}
+}
+
+// Time returns the time of the timestamp, rounded to the nearest nanosecond.
+func (ts Timestamp) Time() time.Time {
+	secs := ts.NTP64 >> 32
+	frac := ts.NTP64 & (ntp64FracPerSec - 1)
+	nanos := (frac*nanosPerSec + ntp64FracPerSec/2) / ntp64FracPerSec
+	return time.Unix(int64(secs), int64(nanos))
+}
+
+// Compare returns -1, 0 or +1 depending on whether ts is before, equal to
+// or after other. IDs are compared as 128-bit unsigned integers.
+func (ts Timestamp) Compare(other Timestamp) int {
+	switch {
+	case ts.NTP64 < other.NTP64:
+		return -1
+	case ts.NTP64 > other.NTP64:
+		return 1
+	}
+	for i := len(ts.ID) - 1; i >= 0; i-- {
+		switch {
+		case ts.ID[i] < other.ID[i]:
+			return -1
+		case ts.ID[i] > other.ID[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Before reports whether ts is ordered before other.
+func (ts Timestamp) Before(other Timestamp) bool {
+	return ts.Compare(other) < 0
+}
+
+// After reports whether ts is ordered after other.
+func (ts Timestamp) After(other Timestamp) bool {
+	return ts.Compare(other) > 0
+}
+
+// String returns the timestamp in the same format zenoh uses:
+// the NTP64 time in decimal, a slash, and the ZenohID.
+func (ts Timestamp) String() string {
+	return strconv.FormatUint(ts.NTP64, 10) + "/" + ts.ID.String()
+}
+
+// ParseTimestamp parses the string form of a Timestamp, as returned by String.
+func ParseTimestamp(s string) (Timestamp, error) {
+	timePart, idPart, ok := strings.Cut(s, "/")
+	if !ok {
+		return Timestamp{}, ErrInvalidTimestamp
+	}
+	ntp64, err := strconv.ParseUint(timePart, 10, 64)
+	if err != nil {
+		return Timestamp{}, ErrInvalidTimestamp
+	}
+	id, err := ParseZenohID(idPart)
+	if err != nil {
+		return Timestamp{}, ErrInvalidTimestamp
+	}
+	return Timestamp{NTP64: ntp64, ID: id}, nil
+}
+
+func timestampFromCGO(ts *cgo.TimestampData) *Timestamp {
+	if ts == nil {
+		return nil
+	}
+	return &Timestamp{NTP64: ts.NTP64, ID: ZenohID(ts.ID)}
+}
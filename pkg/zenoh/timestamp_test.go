@@ -0,0 +1,155 @@
+package zenoh
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/wind-c/zenoh-go/internal/cgo"
+)
+
+func TestTimestamp_TimeRoundTrip(t *testing.T) {
+	tests := []time.Time{
+		time.Unix(0, 0),
+		time.Unix(1700000000, 0),
+		time.Unix(1700000000, 1),
+		time.Unix(1700000000, 123456789),
+		time.Unix(1700000000, 999999999),
+	}
+
+	for _, want := range tests {
+		t.Run(want.String(), func(t *testing.T) {
+			ts := TimestampFromTime(want, ZenohID{1})
+			if got := ts.Time(); !got.Equal(want) {
+				t.Errorf("Time() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestTimestamp_NTP64Layout(t *testing.T) {
+	ts := Timestamp{NTP64: 1700000000<<32 | 1<<31}
+	want := time.Unix(1700000000, 500000000)
+	if got := ts.Time(); !got.Equal(want) {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+
+	// 1ns is 4.29 fractions, truncated like zenoh does.
+	if got := TimestampFromTime(time.Unix(1700000000, 1), ZenohID{}).NTP64; got != 1700000000<<32|4 {
+		t.Errorf("NTP64 = %#x, want %#x", got, uint64(1700000000<<32|4))
+	}
+	// The last fraction of a second rounds up to the next second.
+	ts = Timestamp{NTP64: 1700000000<<32 | 0xffffffff}
+	if got := ts.Time(); !got.Equal(time.Unix(1700000001, 0)) {
+		t.Errorf("Time() = %v, want %v", got, time.Unix(1700000001, 0))
+	}
+}
+
+func TestTimestamp_BeforeEpoch(t *testing.T) {
+	ts := TimestampFromTime(time.Unix(-10, 0), ZenohID{1})
+	if ts.NTP64 != 0 {
+		t.Errorf("NTP64 = %d, want 0", ts.NTP64)
+	}
+}
+
+func TestTimestamp_Compare(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Timestamp
+		want int
+	}{
+		{"equal", Timestamp{NTP64: 5, ID: ZenohID{1}}, Timestamp{NTP64: 5, ID: ZenohID{1}}, 0},
+		{"earlier time", Timestamp{NTP64: 4, ID: ZenohID{9}}, Timestamp{NTP64: 5, ID: ZenohID{1}}, -1},
+		{"later time", Timestamp{NTP64: 6, ID: ZenohID{1}}, Timestamp{NTP64: 5, ID: ZenohID{9}}, 1},
+		{"same time lower id", Timestamp{NTP64: 5, ID: ZenohID{1}}, Timestamp{NTP64: 5, ID: ZenohID{2}}, -1},
+		{"id high byte dominates", Timestamp{NTP64: 5, ID: ZenohID{0xff}}, Timestamp{NTP64: 5, ID: ZenohID{15: 1}}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Compare(tt.b); got != tt.want {
+				t.Errorf("Compare() = %d, want %d", got, tt.want)
+			}
+			if got := tt.b.Compare(tt.a); got != -tt.want {
+				t.Errorf("reverse Compare() = %d, want %d", got, -tt.want)
+			}
+			if tt.a.Before(tt.b) != (tt.want < 0) || tt.a.After(tt.b) != (tt.want > 0) {
+				t.Error("Before()/After() disagree with Compare()")
+			}
+		})
+	}
+}
+
+func TestTimestamp_LastWriterWins(t *testing.T) {
+	now := time.Now()
+	writes := []Timestamp{
+		TimestampFromTime(now, ZenohID{2}),
+		TimestampFromTime(now.Add(time.Millisecond), ZenohID{1}),
+		TimestampFromTime(now, ZenohID{3}),
+	}
+	sort.Slice(writes, func(i, j int) bool { return writes[i].Before(writes[j]) })
+	if writes[2].ID != (ZenohID{1}) || writes[1].ID != (ZenohID{3}) {
+		t.Errorf("unexpected order: %v", writes)
+	}
+}
+
+func TestTimestamp_StringRoundTrip(t *testing.T) {
+	ts := Timestamp{NTP64: 7301230584526503936, ID: ZenohID{0x34, 0x12, 15: 0xab}}
+	s := ts.String()
+	if s != "7301230584526503936/ab000000000000000000000000001234" {
+		t.Errorf("String() = %q", s)
+	}
+	got, err := ParseTimestamp(s)
+	if err != nil {
+		t.Fatalf("ParseTimestamp() error = %v", err)
+	}
+	if got != ts {
+		t.Errorf("ParseTimestamp() = %v, want %v", got, ts)
+	}
+}
+
+func TestParseTimestamp_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"123",
+		"abc/1",
+		"123/",
+		"123/xyz",
+		"-1/1",
+		"123/" + "1234567890abcdef1234567890abcdef0",
+	}
+
+	for _, s := range tests {
+		t.Run(s, func(t *testing.T) {
+			if _, err := ParseTimestamp(s); err != ErrInvalidTimestamp {
+				t.Errorf("ParseTimestamp(%q) error = %v, want %v", s, err, ErrInvalidTimestamp)
+			}
+		})
+	}
+}
+
+func TestTimestamp_CGORoundTrip(t *testing.T) {
+	if timestampFromCGO(nil) != nil || zenohTimestampFromCGO(nil) != nil {
+		t.Error("nil timestamp should convert to nil")
+	}
+	var nilTs *ZenohTimestamp
+	if nilTs.toCGO() != nil {
+		t.Error("nil zenoh timestamp should convert to nil")
+	}
+
+	data := &cgo.TimestampData{NTP64: 42, ID: [cgo.ZIDSize]byte{7}}
+	want := Timestamp{NTP64: 42, ID: ZenohID{7}}
+	if got := timestampFromCGO(data); *got != want {
+		t.Errorf("timestampFromCGO() = %v, want %v", got, want)
+	}
+	zts := zenohTimestampFromCGO(data)
+	if got := zts.Timestamp(); got != want {
+		t.Errorf("Timestamp() = %v, want %v", got, want)
+	}
+	if zts.String() != want.String() {
+		t.Errorf("String() = %q, want %q", zts.String(), want.String())
+	}
+	if got := zts.toCGO(); got == nil || got.NTP64 != 42 || got == data {
+		t.Errorf("toCGO() = %v, want a copy of %v", got, data)
+	}
+}
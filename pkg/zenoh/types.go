@@ -26,9 +26,14 @@
 package zenoh
 
 import (
+	"encoding/hex"
 	"errors"
 	"log"
+	"strings"
+	"time"
 	"unsafe"
+
+	"github.com/wind-c/zenoh-go/internal/cgo"
 )
 
 // =============================================================================
@@ -38,6 +43,9 @@ import (
 // ErrInvalidValue is returned when an operation is performed on an invalid owned type.
 var ErrInvalidValue = errors.New("invalid zenoh value")
 
+// ErrInvalidZenohID is returned when a string is not a valid ZenohID.
+var ErrInvalidZenohID = errors.New("invalid zenoh id")
+
 // ErrDropFailed is returned when dropping a resource fails.
 var ErrDropFailed = errors.New("failed to drop zenoh resource")
 
@@ -155,11 +163,65 @@ func (c *Config) IsValid() bool {
 // Session Types
 // =============================================================================
 
+// ZenohID is the unique identifier of a zenoh node.
+// It is stored in the same little-endian layout as z_id_t in zenoh-c.
+type ZenohID [16]byte
+
+// IsValid returns true if the ZenohID is not all zeros.
+func (id ZenohID) IsValid() bool {
+	return id != ZenohID{}
+}
+
+// String returns the ZenohID in the same format zenoh uses:
+// lowercase hexadecimal, most significant byte first, without leading zeros.
+func (id ZenohID) String() string {
+	var be [16]byte
+	for i := range id {
+		be[i] = id[len(id)-1-i]
+	}
+	s := strings.TrimLeft(hex.EncodeToString(be[:]), "0")
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+// SourceInfo identifies the entity that issued a sample and the sequence
+// number it gave to the sample.
+type SourceInfo struct {
+	ZID ZenohID // Zenoh ID of the session that issued the sample
+	EID uint32  // Entity ID of the publisher or querier within that session
+	SN  uint32  // Sequence number of the sample
+}
+
+// ParseZenohID parses the string form of a ZenohID, as returned by String.
+func ParseZenohID(s string) (ZenohID, error) {
+	var id ZenohID
+	if s == "" || len(s) > 2*len(id) {
+		return id, ErrInvalidZenohID
+	}
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	be, err := hex.DecodeString(s)
+	if err != nil {
+		return id, ErrInvalidZenohID
+	}
+	for i, b := range be {
+		id[len(be)-1-i] = b
+	}
+	return id, nil
+}
+
 // SessionInfo contains information about a zenoh session.
+//
+// WhatAmI and Locators are not reported by zenoh-c and are left empty.
 type SessionInfo struct {
-	ZID      []byte   // Zenoh ID (unique identifier)
-	WhatAmI  string   // Role: "client", "router", or "peer"
-	Locators []string // Connection addresses
+	ZID      ZenohID   // Zenoh ID of this session
+	Peers    []ZenohID // Zenoh IDs of the connected peers
+	Routers  []ZenohID // Zenoh IDs of the connected routers
+	WhatAmI  string    // Role: "client", "router", or "peer"
+	Locators []string  // Connection addresses
 }
 
 // OwnedSession represents a zenoh session that owns its resources.
@@ -189,38 +251,10 @@ func NewOwnedSession() (*OwnedSession, error) {
 	return nil, errors.New("session creation requires zenoh-c bindings (see internal/cgo)")
 }
 
-// FromOwnedSession creates a Session from an existing owned session.
-// The returned Session is a loaned reference that does not own resources.
-func FromOwnedSession(owned *OwnedSession) (*Session, error) {
-	if owned == nil || !owned.IsValid() {
-		return nil, ErrInvalidValue
-	}
-	// Implemented in internal/cgo
-	return nil, errors.New("session loan requires zenoh-c bindings (see internal/cgo)")
-}
-
-// Drop releases the underlying zenoh session resources.
-// After calling Drop, the OwnedSession is invalidated.
-//
-// It is safe to call Drop multiple times; subsequent calls are no-ops.
-func (s *OwnedSession) Drop() error {
-	if s.ptr == 0 {
-		return nil
-	}
-	log.Print("[zenoh] session dropped")
-	s.ptr = 0
-	return nil
-}
-
 // IsValid returns true if the OwnedSession contains a valid zenoh session.
 // Returns false if the session is nil or has been dropped.
 func (s *OwnedSession) IsValid() bool {
-	return s.ptr != 0
-}
-
-// Info returns session information including ZID, WhatAmI, and locators.
-func (s *OwnedSession) Info() (*SessionInfo, error) {
-	return nil, errors.New("Session.Info requires zenoh-c bindings")
+	return s != nil && s.ptr != 0
 }
 
 // Session is a loaned zenoh session reference.
@@ -239,17 +273,7 @@ type Session struct {
 // IsValid returns true if the Session reference is valid.
 // Returns false if the Session is nil or was derived from a dropped OwnedSession.
 func (s *Session) IsValid() bool {
-	return s.ptr != 0
-}
-
-// Close closes the zenoh session.
-// Note: For OwnedSession, use Drop() instead.
-func (s *Session) Close() error {
-	if s.ptr == 0 {
-		return nil
-	}
-	// Implemented in internal/cgo
-	return errors.New("session close requires zenoh-c bindings (see internal/cgo)")
+	return s != nil && s.ptr != 0
 }
 
 // =============================================================================
@@ -266,14 +290,15 @@ func (s *Session) Close() error {
 //
 // Example:
 //
-//	keyExpr, err := zenoh.NewOwnedKeyExpr("demo/example/*")
+//	keyExpr, err := session.DeclareKeyExpr("demo/example/*")
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
-//	defer keyExpr.Drop()
+//	defer session.UndeclareKeyExpr(keyExpr)
 type OwnedKeyExpr struct {
 	ptr   uintptr
 	owned unsafe.Pointer
+	expr  string
 }
 
 // NewOwnedKeyExpr creates a new owned key expression from a string.
@@ -292,24 +317,12 @@ func FromOwnedKeyExpr(owned *OwnedKeyExpr) (*KeyExpr, error) {
 	if owned == nil || !owned.IsValid() {
 		return nil, ErrInvalidValue
 	}
-	// Implemented in internal/cgo
-	return nil, errors.New("keyexpr loan requires zenoh-c bindings (see internal/cgo)")
-}
-
-// Drop releases the underlying key expression resources.
-// After calling Drop, the OwnedKeyExpr is invalidated.
-func (k *OwnedKeyExpr) Drop() error {
-	if k.ptr == 0 {
-		return nil
-	}
-	log.Print("[zenoh] keyexpr dropped")
-	k.ptr = 0
-	return nil
+	return &KeyExpr{ptr: owned.ptr, expr: owned.expr}, nil
 }
 
 // IsValid returns true if the OwnedKeyExpr is valid.
 func (k *OwnedKeyExpr) IsValid() bool {
-	return k.ptr != 0
+	return k != nil && k.ptr != 0
 }
 
 // KeyExpr is a loaned key expression reference.
@@ -419,18 +432,25 @@ func (p *Publisher) IsValid() bool {
 //	}
 //	defer sub.Drop()
 type OwnedSubscriber struct {
-	ptr uintptr
+	ptr   uintptr
+	owned unsafe.Pointer
 }
 
-// Drop releases the subscriber by undeclareing it.
+// Drop releases the subscriber by undeclaring it. The callback is not
+// called anymore once Drop returns.
 // After calling Drop, the OwnedSubscriber is invalidated.
+//
+// It is safe to call Drop multiple times; subsequent calls are no-ops.
+// This is equivalent to z_undeclare_subscriber() in zenoh-c.
 func (s *OwnedSubscriber) Drop() error {
 	if s == nil || s.ptr == 0 {
 		return nil
 	}
-	log.Print("[zenoh] subscriber dropped")
+	sub := cgo.SubscriberFromOwnedPtr(s.ptr, s.owned)
 	s.ptr = 0
-	return nil
+	s.owned = nil
+	log.Print("[zenoh] subscriber dropped")
+	return sub.Undeclare()
 }
 
 // IsValid returns true if the OwnedSubscriber is valid.
@@ -475,6 +495,7 @@ func (s *Subscriber) IsValid() bool {
 //	defer queryable.Drop()
 type OwnedQueryable struct {
 	ptr    uintptr
+	owned  unsafe.Pointer
 	handle uintptr
 }
 
@@ -543,7 +564,41 @@ type GetOptions struct {
 	// Consolidation specifies the consolidation mode.
 	Consolidation Consolidation
 	// Timeout specifies the query timeout.
-	Timeout int64 // in nanoseconds
+	// If 0, the timeout from the session configuration is used.
+	Timeout time.Duration
+	// Payload is an optional value sent along with the query.
+	Payload []byte
+	// Encoding specifies the encoding of the query payload.
+	Encoding *Encoding
+	// Attachment is optional user metadata sent along with the query.
+	Attachment *Attachment
+	// OnDone, if set, is called once after the last reply has been delivered,
+	// either because all queryables answered or because the timeout expired.
+	// It is also called, before the error is returned, if the query could not
+	// be sent.
+	OnDone func()
+}
+
+// DefaultGetOptions returns default get options.
+func DefaultGetOptions() *GetOptions {
+	return &GetOptions{
+		Target:        QueryTargetBestMatching,
+		Consolidation: ConsolidationAuto,
+	}
+}
+
+// consolidationMode maps a Consolidation to its z_consolidation_mode_t value.
+func (c Consolidation) consolidationMode() int {
+	switch c {
+	case ConsolidationNone:
+		return 0
+	case ConsolidationMonotonic:
+		return 1
+	case ConsolidationLatest:
+		return 2
+	default:
+		return -1
+	}
 }
 
 // Reliability defines the reliability mode for pub/sub.
@@ -565,3 +620,107 @@ const (
 	// CongestionControlDrop drops messages on congestion.
 	CongestionControlDrop CongestionControl = 1
 )
+
+// toCGO returns the mode as a z_congestion_control_t value, or nil if c is nil.
+func (c *CongestionControl) toCGO() *int {
+	if c == nil {
+		return nil
+	}
+	v := int(*c)
+	return &v
+}
+
+// Priority defines the priority of zenoh messages.
+// Lower values are routed ahead of higher ones on a shared link.
+type Priority int
+
+const (
+	// PriorityRealTime is the highest priority, for real-time control traffic.
+	PriorityRealTime Priority = 1
+	// PriorityInteractiveHigh is for high priority interactive traffic.
+	PriorityInteractiveHigh Priority = 2
+	// PriorityInteractiveLow is for low priority interactive traffic.
+	PriorityInteractiveLow Priority = 3
+	// PriorityDataHigh is for high priority data traffic.
+	PriorityDataHigh Priority = 4
+	// PriorityData is the default priority.
+	PriorityData Priority = 5
+	// PriorityDataLow is for low priority data traffic.
+	PriorityDataLow Priority = 6
+	// PriorityBackground is the lowest priority, for bulk background traffic.
+	PriorityBackground Priority = 7
+)
+
+// PriorityDefault is the priority applied when none is specified.
+const PriorityDefault = PriorityData
+
+// String returns the string representation of the Priority.
+func (p Priority) String() string {
+	switch p {
+	case PriorityRealTime:
+		return "real_time"
+	case PriorityInteractiveHigh:
+		return "interactive_high"
+	case PriorityInteractiveLow:
+		return "interactive_low"
+	case PriorityDataHigh:
+		return "data_high"
+	case PriorityData:
+		return "data"
+	case PriorityDataLow:
+		return "data_low"
+	case PriorityBackground:
+		return "background"
+	default:
+		return "unknown"
+	}
+}
+
+// SampleKind defines whether a sample carries a PUT or a DELETE.
+type SampleKind int
+
+const (
+	// SampleKindPut is a sample carrying a value.
+	SampleKindPut SampleKind = 0
+	// SampleKindDelete is a sample signalling the deletion of a key.
+	SampleKindDelete SampleKind = 1
+)
+
+// String returns the string representation of the SampleKind.
+func (k SampleKind) String() string {
+	switch k {
+	case SampleKindPut:
+		return "PUT"
+	case SampleKindDelete:
+		return "DELETE"
+	default:
+		return "unknown"
+	}
+}
+
+// Locality restricts the sessions that a publication or query may reach,
+// or that a subscriber or queryable accepts messages from.
+type Locality int
+
+const (
+	// LocalityAny allows both local and remote sessions.
+	LocalityAny Locality = 0
+	// LocalitySessionLocal allows only the local session.
+	LocalitySessionLocal Locality = 1
+	// LocalityRemote allows only remote sessions.
+	LocalityRemote Locality = 2
+)
+
+// String returns the string representation of the Locality.
+func (l Locality) String() string {
+	switch l {
+	case LocalityAny:
+		return "any"
+	case LocalitySessionLocal:
+		return "session_local"
+	case LocalityRemote:
+		return "remote"
+	default:
+		return "unknown"
+	}
+}
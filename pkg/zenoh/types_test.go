@@ -152,6 +152,13 @@ func TestOwnedSession_Drop(t *testing.T) {
 			t.Errorf("second Drop() error = %v", err)
 		}
 	})
+
+	t.Run("nil session", func(t *testing.T) {
+		var s *OwnedSession
+		if err := s.Drop(); err != nil {
+			t.Errorf("Drop() on nil session error = %v", err)
+		}
+	})
 }
 
 func TestOwnedSession_Open(t *testing.T) {
@@ -186,6 +193,17 @@ func TestOwnedSession_FromOwnedSession(t *testing.T) {
 			t.Errorf("FromOwnedSession(invalid) error = %v, want ErrInvalidValue", err)
 		}
 	})
+
+	t.Run("valid owned", func(t *testing.T) {
+		owned := &OwnedSession{ptr: 1}
+		loaned, err := FromOwnedSession(owned)
+		if err != nil {
+			t.Fatalf("FromOwnedSession() error = %v", err)
+		}
+		if !loaned.IsValid() {
+			t.Error("FromOwnedSession() should return a valid session")
+		}
+	})
 }
 
 // =============================================================================
@@ -296,6 +314,45 @@ func TestOwnedKeyExpr_FromOwnedKeyExpr(t *testing.T) {
 	})
 }
 
+func TestOwnedKeyExpr_String(t *testing.T) {
+	var nilKeyExpr *OwnedKeyExpr
+	if nilKeyExpr.String() != "" || nilKeyExpr.IsValid() {
+		t.Error("nil OwnedKeyExpr should be invalid with an empty string")
+	}
+
+	k := &OwnedKeyExpr{ptr: 1, expr: "demo/example"}
+	if k.String() != "demo/example" || k.Cede() != "demo/example" {
+		t.Errorf("String() = %q, Cede() = %q, want %q", k.String(), k.Cede(), "demo/example")
+	}
+	loaned, err := FromOwnedKeyExpr(k)
+	if err != nil {
+		t.Fatalf("FromOwnedKeyExpr() error = %v", err)
+	}
+	if loaned.String() != "demo/example" {
+		t.Errorf("FromOwnedKeyExpr().String() = %q, want %q", loaned.String(), "demo/example")
+	}
+
+	if err := k.Drop(); err != nil {
+		t.Fatalf("Drop() error = %v", err)
+	}
+	if k.String() != "" {
+		t.Errorf("String() after Drop() = %q, want empty", k.String())
+	}
+}
+
+func TestOwnedSession_DeclareKeyExpr_Validation(t *testing.T) {
+	var nilSession *OwnedSession
+	if _, err := nilSession.DeclareKeyExpr("demo/example"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("DeclareKeyExpr() on nil session error = %v, want ErrInvalidValue", err)
+	}
+	if _, err := (&OwnedSession{}).DeclareKeyExpr("demo/example"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("DeclareKeyExpr() on invalid session error = %v, want ErrInvalidValue", err)
+	}
+	if _, err := (&OwnedSession{ptr: 1}).DeclareKeyExpr(""); !errors.Is(err, ErrInvalidKeyExpr) {
+		t.Errorf("DeclareKeyExpr(\"\") error = %v, want ErrInvalidKeyExpr", err)
+	}
+}
+
 // =============================================================================
 // KeyExpr (Loaned) Tests
 // =============================================================================
@@ -679,3 +736,117 @@ func TestResult_Error(t *testing.T) {
 		}
 	})
 }
+
+func TestPriority_String(t *testing.T) {
+	tests := []struct {
+		priority Priority
+		expected string
+	}{
+		{PriorityRealTime, "real_time"},
+		{PriorityInteractiveHigh, "interactive_high"},
+		{PriorityInteractiveLow, "interactive_low"},
+		{PriorityDataHigh, "data_high"},
+		{PriorityData, "data"},
+		{PriorityDataLow, "data_low"},
+		{PriorityBackground, "background"},
+		{Priority(0), "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := tt.priority.String(); got != tt.expected {
+				t.Errorf("Priority.String() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestZenohID_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       ZenohID
+		expected string
+	}{
+		{"zero", ZenohID{}, "0"},
+		{"low byte", ZenohID{0x01}, "1"},
+		{"little endian", ZenohID{0x34, 0x12}, "1234"},
+		{"high byte", ZenohID{15: 0xab}, "ab000000000000000000000000000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.id.String(); got != tt.expected {
+				t.Errorf("ZenohID.String() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseZenohID(t *testing.T) {
+	tests := []string{"0", "1", "1234", "abc", "ab000000000000000000000000001234"}
+	for _, s := range tests {
+		t.Run(s, func(t *testing.T) {
+			id, err := ParseZenohID(s)
+			if err != nil {
+				t.Fatalf("ParseZenohID() error = %v", err)
+			}
+			if got := id.String(); got != s {
+				t.Errorf("String() = %q, want %q", got, s)
+			}
+		})
+	}
+
+	for _, s := range []string{"", "xyz", "1234567890abcdef1234567890abcdef0"} {
+		if _, err := ParseZenohID(s); err != ErrInvalidZenohID {
+			t.Errorf("ParseZenohID(%q) error = %v, want %v", s, err, ErrInvalidZenohID)
+		}
+	}
+}
+
+func TestZenohID_IsValid(t *testing.T) {
+	if (ZenohID{}).IsValid() {
+		t.Error("zero ZenohID should not be valid")
+	}
+	if !(ZenohID{1}).IsValid() {
+		t.Error("non-zero ZenohID should be valid")
+	}
+}
+
+func TestSampleKind_String(t *testing.T) {
+	tests := []struct {
+		kind     SampleKind
+		expected string
+	}{
+		{SampleKindPut, "PUT"},
+		{SampleKindDelete, "DELETE"},
+		{SampleKind(9), "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := tt.kind.String(); got != tt.expected {
+				t.Errorf("SampleKind.String() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLocality_String(t *testing.T) {
+	tests := []struct {
+		locality Locality
+		expected string
+	}{
+		{LocalityAny, "any"},
+		{LocalitySessionLocal, "session_local"},
+		{LocalityRemote, "remote"},
+		{Locality(9), "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := tt.locality.String(); got != tt.expected {
+				t.Errorf("Locality.String() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}